@@ -21,16 +21,222 @@
 package xmss
 
 import (
-	"crypto/rand"
+	"bytes"
+	"crypto/sha512"
 	"encoding/binary"
+	"hash"
+	"io"
+	"sync"
 
 	sha256 "github.com/AidosKuneen/sha256-simd"
+	"golang.org/x/crypto/sha3"
 )
 
 var (
 	zero64 = make([]byte, 64)
 )
 
+//hashFunc is the set of keyed hash functions F, H, H_msg and PRF
+//for one hash function and output length n.
+type hashFunc interface {
+	size() int
+	hashMsg(key, m []byte) []byte
+	digest() digester
+	hashF(s *scratch, key, m, out []byte)
+	hashH(s *scratch, key, m1, m2, out []byte)
+	prf(s *scratch, key, m, out []byte)
+	newPRF(seed []byte) *prf
+	setPRF(p *prf, seed, buf []byte)
+}
+
+//scratch is the working memory of one goroutine for signing, verifying,
+//chain, randHash and the hash functions, which is allocated once so that
+//they do not allocate. A scratch must not be used by two goroutines at once.
+type scratch struct {
+	stat   [8]uint32          //SHA-256 state
+	buf    [64]byte           //SHA-256 block, or toByte(padding, n)
+	msg    [32]byte           //PRF input of sumInt
+	adr    [32]byte           //address of hashNodes and seqChain
+	ots    [32]byte           //address of the one-time key of a leaf or signature
+	key    [64]byte           //key of F and H
+	bm     [2][64]byte        //bitmasks of F and H
+	xor    [2][64]byte        //masked inputs of F and H
+	seed   [64]byte           //seed of a WOTS+ private key
+	seedP  prf                //PRF of seed
+	digits [2*64 + wlen2]byte //base-w digits of a message and its checksum
+	r      [3 * 64]byte       //key of H_msg
+	hmsg   [64]byte           //H_msg of the message
+	node   [64]byte           //node of the traversal
+	sk     wotsPrivKey        //WOTS+ private key of a leaf or signature
+	pk     wotsPubKey         //WOTS+ public key of a leaf
+	ws     [][]byte           //WOTS+ signature in the signature being made
+	cs     []wotsChain        //chains of a WOTS+ key
+	ls     *laneState         //state of the chains hashed in lockstep
+	pub    *prf               //PRF of the last public seed
+	d      digester           //digester of dFor, reused
+	dFor   *digestHash        //hash functions of d
+	md     digester           //digester of H_msg for mdFor, reused
+	mdFor  hashFunc           //hash functions of md
+	rd     bytes.Reader       //reader of the message of Sign or Verify
+	xs     *xmssSig           //parsed XMSS signature
+	mts    *xmssMTSig         //parsed XMSS^MT signature
+}
+
+func newScratch() *scratch {
+	return &scratch{}
+}
+
+//scratches holds the scratch of verifying goroutines, which do not own one.
+var scratches = sync.Pool{
+	New: func() interface{} {
+		return newScratch()
+	},
+}
+
+//initSHA256 sets the SHA-256 state to the initial value and returns it.
+func (s *scratch) initSHA256() []uint32 {
+	s.stat = [8]uint32{
+		sha256.Init0,
+		sha256.Init1,
+		sha256.Init2,
+		sha256.Init3,
+		sha256.Init4,
+		sha256.Init5,
+		sha256.Init6,
+		sha256.Init7,
+	}
+	return s.stat[:]
+}
+
+//prf returns the PRF with h for the public seed, which is computed only
+//when seed differs from the last one.
+func (s *scratch) prf(h hashFunc, seed []byte) *prf {
+	if s.pub == nil || s.pub.hash != h || !bytes.Equal(s.pub.seed, seed) {
+		s.pub = h.newPRF(append([]byte(nil), seed...))
+	}
+	return s.pub
+}
+
+//seedPRF returns the PRF with h for seed, the seed of a WOTS+ private key.
+//The PRF is stored in s.
+func (s *scratch) seedPRF(h hashFunc, seed []byte) *prf {
+	h.setPRF(&s.seedP, seed, s.buf[:])
+	return &s.seedP
+}
+
+//wots returns a WOTS+ private key and a public key of n bytes elements.
+func (s *scratch) wots(n int) (wotsPrivKey, wotsPubKey) {
+	if len(s.sk) != wlen(n) || len(s.sk[0]) != n {
+		s.sk = make(wotsPrivKey, wlen(n))
+		s.pk = make(wotsPubKey, wlen(n))
+		for i := range s.sk {
+			s.sk[i] = make([]byte, n)
+			s.pk[i] = make([]byte, n)
+		}
+	}
+	return s.sk, s.pk
+}
+
+//chains returns n chains to be filled by the caller.
+func (s *scratch) chains(n int) []wotsChain {
+	if cap(s.cs) < n {
+		s.cs = make([]wotsChain, n)
+	}
+	return s.cs[:n]
+}
+
+//otsAddr returns the address of the one-time key idx of tree at layer.
+func (s *scratch) otsAddr(layer uint32, tree uint64, idx uint32) addr {
+	a := addr(s.ots[:])
+	copy(a, zero64)
+	a.set(adrLayer, layer)
+	a.setTree(tree)
+	a.set(adrOTS, idx)
+	return a
+}
+
+//wotsSig returns the WOTS+ signature of n bytes elements in b.
+func (s *scratch) wotsSig(b []byte, n int) wotsSig {
+	s.ws = slices(s.ws, b, wlen(n), n)
+	return s.ws
+}
+
+//xmssSig returns the XMSS signature of s to parse into.
+func (s *scratch) xmssSig() *xmssSig {
+	if s.xs == nil {
+		s.xs = &xmssSig{xmssSigBody: &xmssSigBody{}}
+	}
+	return s.xs
+}
+
+//xmssMTSig returns the XMSS^MT signature of s to parse into.
+func (s *scratch) xmssMTSig() *xmssMTSig {
+	if s.mts == nil {
+		s.mts = &xmssMTSig{}
+	}
+	return s.mts
+}
+
+//lanes returns the state for chainLanes.
+func (s *scratch) lanes() *laneState {
+	if s.ls == nil {
+		s.ls = &laneState{}
+	}
+	return s.ls
+}
+
+//digester returns the reset digester of h.
+func (s *scratch) digester(h *digestHash) digester {
+	if s.dFor != h {
+		s.d = h.newDigest()
+		s.dFor = h
+	} else {
+		s.d.Reset()
+	}
+	return s.d
+}
+
+//msgHash returns the reset digester of H_msg with h, to which
+//toByte(2, n) and key are written.
+func (s *scratch) msgHash(h hashFunc, key []byte) digester {
+	if s.mdFor != h {
+		s.md = h.digest()
+		s.mdFor = h
+	} else {
+		s.md.Reset()
+	}
+	n := h.size()
+	fixed := s.buf[:n]
+	copy(fixed, zero64)
+	fixed[n-1] = 0x2
+	s.md.Write(fixed)
+	s.md.Write(key)
+	return s.md
+}
+
+var (
+	sha256Func   hashFunc = sha256Hash{}
+	sha512Func   hashFunc = &digestHash{n: 64, newDigest: newSHA512}
+	shake128Func hashFunc = &digestHash{n: 32, newDigest: newShake128}
+	shake256Func hashFunc = &digestHash{n: 64, newDigest: newShake256}
+)
+
+//hashFor returns the hash functions for hash function family f and
+//output length n, or nil if they are not implemented.
+func hashFor(f HashFunc, n uint32) hashFunc {
+	switch {
+	case f == SHA2 && n == 32:
+		return sha256Func
+	case f == SHA2 && n == 64:
+		return sha512Func
+	case f == SHAKE && n == 32:
+		return shake128Func
+	case f == SHAKE && n == 64:
+		return shake256Func
+	}
+	return nil
+}
+
 const (
 	adrLayer = 0
 	//adrTree  = 4
@@ -54,8 +260,16 @@ func (o addr) setTree(value uint64) {
 	binary.BigEndian.PutUint64(o[4:], value)
 }
 
+//sha256Hash implements the hash functions with SHA-256 and n=32.
+//F, H and PRF are computed block by block on the SHA-256 state.
+type sha256Hash struct{}
+
+func (sha256Hash) size() int {
+	return 32
+}
+
 //key:arbital, m:arbital bytes
-func hashMsg(key, m []byte) []byte {
+func (sha256Hash) hashMsg(key, m []byte) []byte {
 	fixed := make([]byte, 32)
 	fixed[31] = 0x2
 	h := sha256.New()
@@ -65,19 +279,15 @@ func hashMsg(key, m []byte) []byte {
 	return h.Sum(nil)
 }
 
+func (sha256Hash) digest() digester {
+	return sumDigester{sha256.New()}
+}
+
 //key:32bytes, m:32bytes
-func hashF(key, m, out []byte) {
-	stat := []uint32{
-		sha256.Init0,
-		sha256.Init1,
-		sha256.Init2,
-		sha256.Init3,
-		sha256.Init4,
-		sha256.Init5,
-		sha256.Init6,
-		sha256.Init7,
-	}
-	buf := make([]byte, 64)
+func (sha256Hash) hashF(s *scratch, key, m, out []byte) {
+	stat := s.initSHA256()
+	buf := s.buf[:]
+	copy(buf, zero64[:32])
 	copy(buf[32:], key)
 	sha256.Block(stat, buf)
 	copy(buf, m)
@@ -90,18 +300,10 @@ func hashF(key, m, out []byte) {
 }
 
 //key:32bytes, m:64bytes
-func hashH(key, m1, m2, out []byte) {
-	stat := []uint32{
-		sha256.Init0,
-		sha256.Init1,
-		sha256.Init2,
-		sha256.Init3,
-		sha256.Init4,
-		sha256.Init5,
-		sha256.Init6,
-		sha256.Init7,
-	}
-	buf := make([]byte, 64)
+func (sha256Hash) hashH(s *scratch, key, m1, m2, out []byte) {
+	stat := s.initSHA256()
+	buf := s.buf[:]
+	copy(buf, zero64[:32])
 	buf[31] = 0x1
 	copy(buf[32:], key)
 	sha256.Block(stat, buf)
@@ -116,61 +318,198 @@ func hashH(key, m1, m2, out []byte) {
 	sha256.Int2Bytes(stat, out)
 }
 
+//key:32bytes, m:32bytes
+func (h sha256Hash) prf(s *scratch, key, m, out []byte) {
+	h.newPRF(key).sum(s, m, out)
+}
+
 //prf is for getting value from peudo random function.
 type prf struct {
 	seed   []byte
-	block1 []uint32
+	hash   hashFunc
+	block1 []uint32  //SHA-256 state after the first block, nil for other hashes
+	state  [8]uint32 //memory of block1
 }
 
 //newPRF returns PRF.
 //seed must be 32bytes.
-func newPRF(seed []byte) *prf {
+func (h sha256Hash) newPRF(seed []byte) *prf {
 	if seed == nil {
-		seed = make([]byte, 32)
-		if _, err := rand.Read(seed); err != nil {
-			panic(err)
-		}
-	}
-	p := &prf{
-		seed: seed,
-	}
-	p.block1 = []uint32{
-		sha256.Init0,
-		sha256.Init1,
-		sha256.Init2,
-		sha256.Init3,
-		sha256.Init4,
-		sha256.Init5,
-		sha256.Init6,
-		sha256.Init7,
+		panic("xmss: PRF without seed")
 	}
-	buf := make([]byte, 64)
+	p := &prf{}
+	h.setPRF(p, seed, make([]byte, 64))
+	return p
+}
+
+//setPRF sets p to the PRF with seed, computing the first block in buf
+//of 64 bytes.
+func (h sha256Hash) setPRF(p *prf, seed, buf []byte) {
+	p.seed = seed
+	p.hash = h
+	p.state = sha256Init
+	p.block1 = p.state[:]
+	copy(buf, zero64)
 	buf[31] = 0x3
 	copy(buf[32:], seed)
 	sha256.Block(p.block1, buf)
-	return p
 }
 
-//m:32bytes
-func (p *prf) finish(buf, out []byte) {
+//finish hashes the message in the first 32 bytes of s.buf.
+func (p *prf) finish(s *scratch, out []byte) {
+	buf := s.buf[:]
+	copy(buf[32:], zero64)
 	buf[32] = 0x80
 	buf[62] = 0x03
 	// buf[63] = 0x00
-	stat := make([]uint32, 8)
+	stat := s.stat[:]
 	copy(stat, p.block1)
 	sha256.Block(stat, buf)
 	sha256.Int2Bytes(stat, out)
 }
 
 //m:32bytes
-func (p *prf) sum(m, out []byte) {
-	buf := make([]byte, 64)
-	copy(buf, m)
-	p.finish(buf, out)
+func (p *prf) sum(s *scratch, m, out []byte) {
+	if p.block1 == nil {
+		p.hash.prf(s, p.seed, m, out)
+		return
+	}
+	copy(s.buf[:32], m)
+	p.finish(s, out)
 }
 
-func (p *prf) sumInt(m uint32, out []byte) {
-	buf := make([]byte, 64)
-	binary.BigEndian.PutUint32(buf[28:], m)
-	p.finish(buf, out)
+func (p *prf) sumInt(s *scratch, m uint32, out []byte) {
+	mm := s.msg[:]
+	copy(mm, zero64)
+	binary.BigEndian.PutUint32(mm[28:], m)
+	p.sum(s, mm, out)
+}
+
+//digestHash implements the hash functions with SHA-512 (n=64),
+//SHAKE128 (n=32) or SHAKE256 (n=64).
+type digestHash struct {
+	n         int
+	newDigest func() digester
+}
+
+//digester is a hash which computes a digest of the data written to it.
+type digester interface {
+	io.Writer
+	Reset()
+	//sum writes the digest to out, whose length is the output length.
+	sum(out []byte)
+}
+
+//sumDigester is a digester with a fixed output length, like SHA-512.
+type sumDigester struct {
+	hash.Hash
+}
+
+func (d sumDigester) sum(out []byte) {
+	copy(out, d.Sum(out[:0]))
+}
+
+//shakeDigester is a digester with an extendable output length.
+type shakeDigester struct {
+	sha3.ShakeHash
+}
+
+func (d shakeDigester) sum(out []byte) {
+	d.Read(out)
+}
+
+func newSHA512() digester {
+	return sumDigester{sha512.New()}
+}
+
+func newShake128() digester {
+	return shakeDigester{sha3.NewShake128()}
+}
+
+func newShake256() digester {
+	return shakeDigester{sha3.NewShake256()}
+}
+
+func (s *digestHash) size() int {
+	return s.n
+}
+
+//sum writes the digest of toByte(padding, n) || in... to out, using the
+//digester of sc.
+func (s *digestHash) sum(sc *scratch, out []byte, padding byte, in ...[]byte) {
+	d := sc.digester(s)
+	fixed := sc.buf[:s.n]
+	copy(fixed, zero64)
+	fixed[s.n-1] = padding
+	d.Write(fixed)
+	for _, b := range in {
+		d.Write(b)
+	}
+	d.sum(out[:s.n])
+}
+
+//start returns a digester to which toByte(padding, n) is written.
+func (s *digestHash) start(padding byte) digester {
+	fixed := make([]byte, s.n)
+	fixed[s.n-1] = padding
+	d := s.newDigest()
+	d.Write(fixed)
+	return d
+}
+
+//key:3n bytes, m:arbital bytes
+func (s *digestHash) hashMsg(key, m []byte) []byte {
+	out := make([]byte, s.n)
+	d := s.start(0x2)
+	d.Write(key)
+	d.Write(m)
+	d.sum(out)
+	return out
+}
+
+func (s *digestHash) digest() digester {
+	return s.newDigest()
+}
+
+//key:n bytes, m:n bytes
+func (s *digestHash) hashF(sc *scratch, key, m, out []byte) {
+	s.sum(sc, out, 0x0, key, m)
+}
+
+//key:n bytes, m:2n bytes
+func (s *digestHash) hashH(sc *scratch, key, m1, m2, out []byte) {
+	s.sum(sc, out, 0x1, key, m1, m2)
+}
+
+//key:n bytes, m:32 bytes
+func (s *digestHash) prf(sc *scratch, key, m, out []byte) {
+	s.sum(sc, out, 0x3, key, m)
+}
+
+func (s *digestHash) newPRF(seed []byte) *prf {
+	if seed == nil {
+		panic("xmss: PRF without seed")
+	}
+	p := &prf{}
+	s.setPRF(p, seed, nil)
+	return p
+}
+
+//setPRF sets p to the PRF with seed. buf is not used.
+func (s *digestHash) setPRF(p *prf, seed, buf []byte) {
+	p.seed = seed
+	p.hash = s
+	p.block1 = nil
+}
+
+//hashMsgReader returns H_msg(key, m) of the message read from m until EOF,
+//which is stored in s.
+func hashMsgReader(s *scratch, h hashFunc, key []byte, m io.Reader) ([]byte, error) {
+	d := s.msgHash(h, key)
+	if _, err := io.Copy(d, m); err != nil {
+		return nil, err
+	}
+	out := s.hmsg[:h.size()]
+	d.sum(out)
+	return out, nil
 }
@@ -25,6 +25,7 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/binary"
+	"encoding/hex"
 	"testing"
 )
 
@@ -49,7 +50,7 @@ func TestHashes(t *testing.T) {
 	s.Write(m)
 	outC := s.Sum(nil)
 
-	out := hashMsg(key, m)
+	out := sha256Func.hashMsg(key, m)
 	if !bytes.Equal(out, outC) {
 		t.Error("incorrect hashM")
 	}
@@ -61,7 +62,7 @@ func TestHashes(t *testing.T) {
 	s.Write(m)
 	outC = s.Sum(nil)
 
-	hashF(key, m, out)
+	sha256Func.hashF(newScratch(), key, m, out)
 	if !bytes.Equal(out, outC) {
 		t.Error("incorrect hashF")
 	}
@@ -74,7 +75,7 @@ func TestHashes(t *testing.T) {
 	s.Write(m2)
 	outC = s.Sum(nil)
 
-	hashH(key, m, m2, out)
+	sha256Func.hashH(newScratch(), key, m, m2, out)
 	if !bytes.Equal(out, outC) {
 		t.Error("incorrect hashH")
 		t.Log(out)
@@ -87,8 +88,8 @@ func TestHashes(t *testing.T) {
 	s.Write(key)
 	s.Write(m)
 	outC = s.Sum(nil)
-	prf := newPRF(key)
-	prf.sum(m, out)
+	prf := sha256Func.newPRF(key)
+	prf.sum(newScratch(), m, out)
 	if !bytes.Equal(out, outC) {
 		t.Error("incorrect prf")
 	}
@@ -98,7 +99,7 @@ func TestHashes(t *testing.T) {
 	s.Write(key)
 	s.Write(m2)
 	outC = s.Sum(nil)
-	prf.sum(m2, out)
+	prf.sum(newScratch(), m2, out)
 	if !bytes.Equal(out, outC) {
 		t.Error("incorrect prf")
 	}
@@ -111,8 +112,8 @@ func TestHashes(t *testing.T) {
 	s.Write(key)
 	s.Write(mm)
 	outC = s.Sum(nil)
-	prfP := newPRF(key)
-	prfP.sumInt(123, out)
+	prfP := sha256Func.newPRF(key)
+	prfP.sumInt(newScratch(), 123, out)
 	if !bytes.Equal(out, outC) {
 		t.Error("incorrect prfPriv")
 	}
@@ -123,9 +124,134 @@ func TestHashes(t *testing.T) {
 	s.Write(key)
 	s.Write(mm)
 	outC = s.Sum(nil)
-	prfP.sumInt(456, out)
+	prfP.sumInt(newScratch(), 456, out)
 	if !bytes.Equal(out, outC) {
 		t.Error("incorrect prfPriv")
 	}
 
 }
+
+//TestDigestHash checks the construction of F, H, H_msg and PRF shared by
+//the SHAKE and SHA-512 parameter sets: with SHA-256 as its digest,
+//digestHash must reproduce the XMSS-SHA2_10_256 key and signature of
+//TestXMSS, which sha256Hash computes block by block.
+func TestDigestHash(t *testing.T) {
+	skseed, err := hex.DecodeString("b041bf7ca73cc7905aadc1b6460da2e50206652e3d57a61487beb09664da308d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	skprf, err := hex.DecodeString("303f46b2e4248b040a74e7f1eca3ff288b452ef4910739b466225ccadcdb3aae")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubseed, err := hex.DecodeString("115e28fb34d6414cd7cce714895df8c4a674ee1e557f1f3df99370aa8ab7c404")
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("This is a test for XMSS.")
+	sk, _, err := NewXMSSKeyPairWithParams(10, skseed, skprf, pubseed, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := mustSign(t, sk, msg)
+
+	h := sha256Func
+	sha256Func = &digestHash{n: 32, newDigest: func() digester {
+		return sumDigester{sha256.New()}
+	}}
+	defer func() {
+		sha256Func = h
+	}()
+	sk2, pk2, err := NewXMSSKeyPairWithParams(10, skseed, skprf, pubseed, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(sk2.root) != "a959a891573da8633b89e8f21e43eef9fca43a14bd2d71b1cf9ad5706945e752" {
+		t.Error("root of digestHash is incorrect")
+		t.Log(hex.EncodeToString(sk2.root))
+	}
+	sig2 := mustSign(t, sk2, msg)
+	if !bytes.Equal(sig, sig2) {
+		t.Error("signature of digestHash is incorrect")
+	}
+	if !pk2.Verify(sig2, msg) {
+		t.Error("verification of digestHash is incorrect")
+	}
+}
+
+//TestSHAKEDigest checks that the SHAKE parameter sets use SHAKE128 for
+//n = 32 and SHAKE256 for n = 64 with the NIST example values of FIPS 202
+//for the empty message.
+func TestSHAKEDigest(t *testing.T) {
+	vectors := []struct {
+		h    hashFunc
+		want string
+	}{
+		{
+			hashFor(SHAKE, 32),
+			"7f9c2ba4e88f827d616045507605853ed73b8093f6efbc88eb1a6eacfa66ef26",
+		},
+		{
+			hashFor(SHAKE, 64),
+			"46b9dd2b0ba88d13233b3feb743eeb243fcd52ea62b81b82b50c27646ed5762f" +
+				"d75dc4ddd8c0f200cb05019d67b592f6fc821c49479ab48640292eacb3b7c4be",
+		},
+	}
+	for _, v := range vectors {
+		h := v.h.(*digestHash)
+		out := make([]byte, h.n)
+		h.newDigest().sum(out)
+		if hex.EncodeToString(out) != v.want {
+			t.Errorf("SHAKE digest for n = %d is incorrect: %x", h.n, out)
+		}
+	}
+}
+
+func TestHashMsgReader(t *testing.T) {
+	for _, h := range []hashFunc{sha256Func, sha512Func, shake128Func, shake256Func} {
+		key := make([]byte, 3*h.size())
+		if _, err := rand.Read(key); err != nil {
+			t.Fatal(err)
+		}
+		m := make([]byte, 100000)
+		if _, err := rand.Read(m); err != nil {
+			t.Fatal(err)
+		}
+		out, err := hashMsgReader(newScratch(), h, key, bytes.NewReader(m))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(out, h.hashMsg(key, m)) {
+			t.Errorf("incorrect hashM from reader for n = %d", h.size())
+		}
+	}
+}
+
+func TestScratchAllocs(t *testing.T) {
+	for _, h := range []hashFunc{sha256Func, sha512Func, shake128Func, shake256Func} {
+		n := h.size()
+		key := make([]byte, n)
+		m := make([]byte, n)
+		m2 := make([]byte, n)
+		out := make([]byte, n)
+		adr := make(addr, 32)
+		p := h.newPRF(make([]byte, n))
+		s := newScratch()
+		chain(s, m, 0, w-1, p, adr, out)
+		want := append([]byte(nil), out...)
+		allocs := testing.AllocsPerRun(10, func() {
+			h.hashF(s, key, m, out)
+			h.hashH(s, key, m, m2, out)
+			p.sum(s, adr, out)
+			p.sumInt(s, 1, out)
+			randHash(s, m, m2, p, adr, out)
+			chain(s, m, 0, w-1, p, adr, out)
+		})
+		if allocs != 0 {
+			t.Errorf("%d bytes hashes allocate %v times", n, allocs)
+		}
+		if !bytes.Equal(out, want) {
+			t.Errorf("%d bytes chain differs with a used scratch", n)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright (c) 2019 Benjamin Schlosser
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"runtime"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// GenerateKey generates a key pair for params from 3n bytes read from rand,
+// typically crypto/rand.Reader, which are used as SK_SEED || SK_PRF ||
+// PUB_SEED like in the reference implementation of RFC 8391.
+func GenerateKey(rand io.Reader, params *XMSSParameters) (*PrivateKey, error) {
+	return GenerateKeyWithOptions(context.Background(), rand, params, nil)
+}
+
+// GenerateKeyContext is GenerateKey which stops and returns the error of
+// ctx when ctx is done before the tree is computed. If progress is not nil,
+// it is called after every computed leaf with the number of computed leaves
+// and their total 2^h. The calls are serialized, but come from the
+// goroutines computing the tree, so progress should return quickly.
+func GenerateKeyContext(ctx context.Context, rand io.Reader, params *XMSSParameters, progress func(done, total uint64)) (*PrivateKey, error) {
+	return GenerateKeyWithOptions(ctx, rand, params, &Options{Progress: progress})
+}
+
+// Options are the settings of GenerateKeyWithOptions,
+// GenerateKeyMTWithOptions, BatchVerify and VerifyBatch.
+type Options struct {
+	// Concurrency is the number of goroutines which compute the tree and
+	// the WOTS+ chains for the key, now and later, as set by
+	// SetConcurrency, or which verify the signatures of a batch. 1 computes
+	// everything on the calling goroutine, and 0 means GOMAXPROCS.
+	Concurrency int
+	// Progress, if not nil, reports the computed leaves of the tree like
+	// in GenerateKeyContext. For XMSS^MT it is the top layer tree. It is
+	// not used for verifying.
+	Progress func(done, total uint64)
+}
+
+//concurrency returns the number of goroutines of opts, or def if opts is
+//nil.
+func (opts *Options) concurrency(def int) int {
+	switch {
+	case opts == nil:
+		return def
+	case opts.Concurrency > 0:
+		return opts.Concurrency
+	default:
+		return runtime.GOMAXPROCS(-1)
+	}
+}
+
+// GenerateKeyWithOptions is GenerateKeyContext with the settings in opts.
+// A nil opts uses the defaults.
+func GenerateKeyWithOptions(ctx context.Context, rand io.Reader, params *XMSSParameters, opts *Options) (*PrivateKey, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+	seed := make([]byte, 3*params.N)
+	if _, err := io.ReadFull(rand, seed); err != nil {
+		return nil, err
+	}
+	secretKeySeed, secretKeyPRF, publicSeed := splitSeed(seed, params.N)
+	priv, _ := newXMSSKey(*params, secretKeySeed, secretKeyPRF, publicSeed, defaultBDSK(params.Height))
+	priv.SetConcurrency(opts.Concurrency)
+	root, err := priv.initMerkleAtContext(ctx, 0, 0, 0, opts.Progress)
+	if err != nil {
+		return nil, err
+	}
+	copy(priv.root, root)
+	return priv, nil
+}
+
+// GenerateKeyMT is the XMSS^MT counterpart of GenerateKey.
+func GenerateKeyMT(rand io.Reader, params *XMSSMTParameters) (*PrivateKeyMT, error) {
+	return GenerateKeyMTWithOptions(context.Background(), rand, params, nil)
+}
+
+// GenerateKeyMTWithOptions is GenerateKeyMT with the settings in opts,
+// which stops and returns the error of ctx when ctx is done before the top
+// layer tree is computed. A nil opts uses the defaults.
+func GenerateKeyMTWithOptions(ctx context.Context, rand io.Reader, params *XMSSMTParameters, opts *Options) (*PrivateKeyMT, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+	seed := make([]byte, 3*params.N)
+	if _, err := io.ReadFull(rand, seed); err != nil {
+		return nil, err
+	}
+	secretKeySeed, secretKeyPRF, publicSeed := splitSeed(seed, params.N)
+	priv := newXMSSMTKey(*params, secretKeySeed, secretKeyPRF, publicSeed)
+	priv.SetConcurrency(opts.Concurrency)
+	t := priv.newTree()
+	root, err := t.initMerkleAtContext(ctx, 0, params.Layers-1, 0, opts.Progress)
+	if err != nil {
+		return nil, err
+	}
+	copy(t.root, root)
+	priv.trees[params.Layers-1] = t
+	priv.root = t.root
+	return priv, nil
+}
+
+//splitSeed splits the 3n bytes of seed into SK_SEED, SK_PRF and PUB_SEED.
+func splitSeed(seed []byte, n uint32) (secretKeySeed, secretKeyPRF, publicSeed []byte) {
+	return seed[:n:n], seed[n : 2*n : 2*n], seed[2*n:]
+}
+
+// Derivation selects how DeriveKey derives SK_SEED, SK_PRF and PUB_SEED
+// from a secret seed. The output of a derivation never changes, so that a
+// key can be derived again by later versions of this package and by other
+// implementations.
+type Derivation uint32
+
+const (
+	// DerivationHMAC is the derivation of NewXMSSKeyPair. The three seeds
+	// are HMAC(seed, 0x01), HMAC(seed, 0x02) and HMAC(seed, 0x03) with
+	// SHA-256 for n = 32 and SHA-512 for n = 64, so that each has n bytes.
+	DerivationHMAC Derivation = iota
+	// DerivationHKDFv1 reads 3n bytes from HKDF-SHA256 (RFC 5869) with the
+	// seed as input keying material, no salt and the info
+	// "XMSS key derivation v1 " followed by the name of the parameter set,
+	// e.g. "XMSS-SHA2_10_256", and splits them like GenerateKey.
+	DerivationHKDFv1
+)
+
+//hkdfInfoV1 is the prefix of the HKDF info of DerivationHKDFv1.
+const hkdfInfoV1 = "XMSS key derivation v1 "
+
+// DeriveKey derives a key pair for params from seed deterministically with
+// the derivation d. seed must be secret and have at least n bytes of entropy.
+func DeriveKey(d Derivation, params *XMSSParameters, seed []byte) (*PrivateKey, error) {
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+	secretKeySeed, secretKeyPRF, publicSeed, err := deriveKeySeeds(d, params.String(), params.N, seed)
+	if err != nil {
+		return nil, err
+	}
+	priv, _ := newXMSSKeyPair(*params, secretKeySeed, secretKeyPRF, publicSeed, 0, 0)
+	return priv, nil
+}
+
+// DeriveKeyMT is the XMSS^MT counterpart of DeriveKey.
+func DeriveKeyMT(d Derivation, params *XMSSMTParameters, seed []byte) (*PrivateKeyMT, error) {
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+	secretKeySeed, secretKeyPRF, publicSeed, err := deriveKeySeeds(d, params.String(), params.N, seed)
+	if err != nil {
+		return nil, err
+	}
+	priv, _, err := newXMSSMTKeyPair(*params, secretKeySeed, secretKeyPRF, publicSeed)
+	return priv, err
+}
+
+//deriveKeySeeds derives SK_SEED, SK_PRF and PUB_SEED from seed with d for
+//the parameter set of the given name.
+func deriveKeySeeds(d Derivation, name string, n uint32, seed []byte) (secretKeySeed, secretKeyPRF, publicSeed []byte, err error) {
+	if len(seed) < int(n) {
+		return nil, nil, nil, fmt.Errorf("xmss: seed must have at least %d bytes", n)
+	}
+	switch d {
+	case DerivationHMAC:
+		secretKeySeed, secretKeyPRF, publicSeed = deriveSeeds(seed, n)
+		return
+	case DerivationHKDFv1:
+		out := make([]byte, 3*n)
+		if _, err = io.ReadFull(hkdf.New(sha256.New, seed, nil, []byte(hkdfInfoV1+name)), out); err != nil {
+			return
+		}
+		secretKeySeed, secretKeyPRF, publicSeed = splitSeed(out, n)
+		return
+	}
+	return nil, nil, nil, fmt.Errorf("xmss: unknown derivation %d", d)
+}
@@ -0,0 +1,292 @@
+// Copyright (c) 2019 Benjamin Schlosser
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestGenerateKey(t *testing.T) {
+	seed, err := hex.DecodeString("5F706A93A124CB56BE67FF5F1133FD7EB62A36CB182AEF97B9559746DF3F1936" +
+		"F1869736940E984F822EDE862DCB2B2FE592276074879718289C19CBBD84DAAD" +
+		"FD2968D1428A44FED5CACBBA3527B9D96EF721A9C27F8BD693419ED53B7BECA5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	params, err := XMSSParametersByName("XMSS-SHA2_10_256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, err := GenerateKey(bytes.NewReader(seed), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root := strings.ToUpper(hex.EncodeToString(priv.root)); root != "FB9A2A97302A7B6F8113415056C04A23E322643C7D7FA4FEE11B15DC4209060E" {
+		t.Errorf("root is incorrect: %s", root)
+	}
+	if !bytes.Equal(priv.wotsPRF.seed, seed[:32]) || !bytes.Equal(priv.msgPRF.seed, seed[32:64]) || !bytes.Equal(priv.publicSeed, seed[64:]) {
+		t.Error("seeds are not taken in the order SK_SEED || SK_PRF || PUB_SEED")
+	}
+	msg := []byte("test message")
+	if !priv.PublicKey.Verify(mustSign(t, priv, msg), msg) {
+		t.Error("XMSS sig is incorrect")
+	}
+	if _, err := GenerateKey(bytes.NewReader(seed[:95]), params); err == nil {
+		t.Error("a short read must fail")
+	}
+
+	paramsMT := defaultXMSSMTParameters(4, 2)
+	privMT, err := GenerateKeyMT(bytes.NewReader(seed), &paramsMT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, pubMT, err := NewXMSSMTKeyPairWithParams(4, 2, seed[:32], seed[32:64], seed[64:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(privMT.root, pubMT.root) {
+		t.Error("XMSS^MT root is incorrect")
+	}
+}
+
+func TestDeriveKey(t *testing.T) {
+	seed := make([]byte, 32)
+	for i := range seed {
+		seed[i] = byte(i)
+	}
+	sks, skprf, pubseed, err := deriveKeySeeds(DerivationHKDFv1, "XMSS-SHA2_10_256", 32, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "54c525ee7f53b0d17efac55dae9fd34adb24b66036ae58d0135dd6b3be49dabd" +
+		"9e3f2361c2513f3f622026e5db37250b1b1be52fd52869abf134210c1888076f" +
+		"7c22036010c32546bf722084dec3f6e1897dc68b5d1a536d6e1f2447fb6e1c3a"
+	if got := hex.EncodeToString(append(append(sks, skprf...), pubseed...)); got != want {
+		t.Errorf("seeds derived by HKDF v1 are incorrect: %s", got)
+	}
+	if _, _, _, err := deriveKeySeeds(DerivationHKDFv1, "XMSS-SHA2_10_256", 32, seed[:31]); err == nil {
+		t.Error("a short seed must be rejected")
+	}
+	if _, _, _, err := deriveKeySeeds(Derivation(2), "XMSS-SHA2_10_256", 32, seed); err == nil {
+		t.Error("an unknown derivation must be rejected")
+	}
+
+	params := defaultXMSSParameters(4)
+	priv, err := DeriveKey(DerivationHKDFv1, &params, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv2, err := DeriveKey(DerivationHKDFv1, &params, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(priv.root, priv2.root) {
+		t.Error("derived keys differ")
+	}
+	priv3, err := DeriveKey(DerivationHMAC, &params, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, pub, err := NewXMSSKeyPairFromParams(&params, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(priv3.root, pub.root) {
+		t.Error("key derived by HMAC differs from NewXMSSKeyPairFromParams")
+	}
+	if bytes.Equal(priv.root, priv3.root) {
+		t.Error("keys of different derivations must differ")
+	}
+
+	paramsMT := defaultXMSSMTParameters(4, 2)
+	privMT, err := DeriveKeyMT(DerivationHKDFv1, &paramsMT, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, skprf, pubseed, err = deriveKeySeeds(DerivationHKDFv1, paramsMT.String(), 32, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(privMT.msgPRF.seed, skprf) || !bytes.Equal(privMT.publicSeed, pubseed) {
+		t.Error("XMSS^MT key is not derived with its parameter set")
+	}
+}
+
+func TestGenerateKeyContext(t *testing.T) {
+	seed := generateSeed()
+	seed = append(append(seed, seed...), seed...)
+	params := defaultXMSSParameters(6)
+	var calls []uint64
+	priv, err := GenerateKeyContext(context.Background(), bytes.NewReader(seed), &params, func(done, total uint64) {
+		if total != 64 {
+			t.Errorf("total %d is incorrect", total)
+		}
+		calls = append(calls, done)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 64 {
+		t.Errorf("progress is reported %d times", len(calls))
+	}
+	for i, done := range calls {
+		if done != uint64(i)+1 {
+			t.Errorf("progress %d is reported as %d", i+1, done)
+		}
+	}
+	ref, err := GenerateKey(bytes.NewReader(seed), &params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(priv.root, ref.root) {
+		t.Error("root is incorrect")
+	}
+	msg := []byte("test message")
+	if !bytes.Equal(mustSign(t, priv, msg), mustSign(t, ref, msg)) {
+		t.Error("signature is incorrect")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var last uint64
+	_, err = GenerateKeyContext(ctx, bytes.NewReader(seed), &params, func(done, total uint64) {
+		if done == 10 {
+			cancel()
+		}
+		last = done
+	})
+	if err != context.Canceled {
+		t.Errorf("error %v is not context.Canceled", err)
+	}
+	if last >= 64 {
+		t.Error("key generation is not canceled")
+	}
+	if _, err := GenerateKeyContext(ctx, bytes.NewReader(seed), &params, nil); err != context.Canceled {
+		t.Errorf("error %v is not context.Canceled", err)
+	}
+}
+
+func TestGenerateKeyWithOptions(t *testing.T) {
+	seed := generateSeed()
+	seed = append(append(seed, seed...), seed...)
+	params := defaultXMSSParameters(6)
+	ref, err := GenerateKey(bytes.NewReader(seed), &params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	//progress is called serially by the goroutines computing the tree
+	//while they are running, so the most goroutines it sees exceed those
+	//before if any are started.
+	var most int
+	progress := func(done, total uint64) {
+		if n := runtime.NumGoroutine(); n > most {
+			most = n
+		}
+	}
+	before := runtime.NumGoroutine()
+	if _, err := GenerateKeyWithOptions(context.Background(), bytes.NewReader(seed), &params, &Options{Concurrency: 4, Progress: progress}); err != nil {
+		t.Fatal(err)
+	}
+	if most <= before {
+		t.Error("no goroutines are started with a concurrency of 4")
+	}
+	most = 0
+	before = runtime.NumGoroutine()
+	priv, err := GenerateKeyWithOptions(context.Background(), bytes.NewReader(seed), &params, &Options{Concurrency: 1, Progress: progress})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(priv.root, ref.root) {
+		t.Error("root is incorrect")
+	}
+	if !priv.PublicKey.Verify(mustSign(t, priv, []byte("test")), []byte("test")) {
+		t.Error("signature is not verified")
+	}
+	if most > before {
+		t.Errorf("%d goroutines are started with a concurrency of 1", most-before)
+	}
+	//keep the indices of ref and priv equal.
+	mustSign(t, ref, []byte("test"))
+	for i := 0; i < 3; i++ {
+		msg := []byte{byte(i)}
+		sig := mustSign(t, priv, msg)
+		if !bytes.Equal(sig, mustSign(t, ref, msg)) {
+			t.Error("signature is incorrect")
+		}
+		priv.PublicKey.SetConcurrency(3)
+		if !priv.PublicKey.Verify(sig, msg) {
+			t.Error("signature is not verified")
+		}
+		priv.SetConcurrency(i)
+	}
+
+	mtParams := defaultXMSSMTParameters(4, 2)
+	mtRef, err := GenerateKeyMT(bytes.NewReader(seed), &mtParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var leaves uint64
+	mt, err := GenerateKeyMTWithOptions(context.Background(), bytes.NewReader(seed), &mtParams, &Options{
+		Concurrency: 1,
+		Progress: func(done, total uint64) {
+			leaves = total
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if leaves != 4 {
+		t.Errorf("top layer tree has %d leaves", leaves)
+	}
+	if !bytes.Equal(mt.root, mtRef.root) {
+		t.Error("root is incorrect")
+	}
+	for i := 0; i < 5; i++ {
+		msg := []byte{byte(i)}
+		sig, err := mt.Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sigRef, err := mtRef.Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(sig, sigRef) {
+			t.Error("signature is incorrect")
+		}
+		if !mt.PublicKeyMT.Verify(sig, msg) {
+			t.Error("signature is not verified")
+		}
+		mt.SetConcurrency(2 - i%2)
+	}
+}
+
+func TestLogProcs(t *testing.T) {
+	for procs, n := range []uint32{0, 0, 1, 1, 2, 2, 2, 2, 3} {
+		if l := logProcs(procs); l != n {
+			t.Errorf("logProcs(%d) is %d instead of %d", procs, l, n)
+		}
+	}
+}
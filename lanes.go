@@ -0,0 +1,208 @@
+// Copyright (c) 2019 Benjamin Schlosser
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import (
+	"encoding/binary"
+	"math/bits"
+
+	sha256 "github.com/AidosKuneen/sha256-simd"
+)
+
+//lanes is the number of WOTS+ chains which chainLanes advances in lockstep.
+const lanes = 8
+
+var (
+	//useLanes is set if hashing the chains in lockstep with blockLanes is
+	//faster than hashing chain by chain.
+	useLanes = false
+	//blockLanes runs the SHA-256 compression function in every lane.
+	blockLanes = blockLanesGeneric
+)
+
+var sha256Init = [8]uint32{
+	sha256.Init0,
+	sha256.Init1,
+	sha256.Init2,
+	sha256.Init3,
+	sha256.Init4,
+	sha256.Init5,
+	sha256.Init6,
+	sha256.Init7,
+}
+
+//laneState is the working memory of chainLanes. The SHA-256 states and
+//message schedules are stored word by word with the words of all lanes
+//next to each other, so a lane is a column.
+type laneState struct {
+	stat [8][lanes]uint32  //SHA-256 states
+	w    [64][lanes]uint32 //message schedules, the first 16 words are the blocks
+	x    [lanes][8]uint32  //current value of the chain in each lane
+	key  [lanes][8]uint32  //keys of F
+	bm   [lanes][8]uint32  //bitmasks of F
+	cur  [lanes]int        //index of the chain in each lane
+	step [lanes]byte       //number of steps done in each lane
+}
+
+//chainLanes computes the chains of cs like seqChain, advancing the chains
+//of all lanes in lockstep. A lane takes the next chain of cs when its chain
+//is done. p must be the PRF of SHA-256 with n=32.
+func chainLanes(s *scratch, addrs addr, p *prf, cs []wotsChain, first uint32) {
+	ls := s.lanes()
+	var adr [8]uint32
+	for j := range adr {
+		adr[j] = binary.BigEndian.Uint32(addrs[4*j:])
+	}
+	active, next := 0, 0
+	for {
+		for active < lanes && next < len(cs) {
+			c := &cs[next]
+			if c.steps == 0 {
+				copy(c.out, c.in)
+			} else {
+				ls.cur[active] = next
+				ls.step[active] = 0
+				for j := range ls.x[active] {
+					ls.x[active][j] = binary.BigEndian.Uint32(c.in[4*j:])
+				}
+				active++
+			}
+			next++
+		}
+		if active == 0 {
+			return
+		}
+		ls.prf(p, &adr, cs, first, active, 0, &ls.key)
+		ls.prf(p, &adr, cs, first, active, 1, &ls.bm)
+		ls.hashF(active)
+		for l := active - 1; l >= 0; l-- {
+			ls.step[l]++
+			c := &cs[ls.cur[l]]
+			if ls.step[l] < c.steps {
+				continue
+			}
+			for j, v := range ls.x[l] {
+				binary.BigEndian.PutUint32(c.out[4*j:], v)
+			}
+			active--
+			ls.cur[l], ls.step[l], ls.x[l] = ls.cur[active], ls.step[active], ls.x[active]
+		}
+	}
+}
+
+//prf sets out to PRF(SEED, ADRS) with the key and mask index km for the
+//next hash of the chain in every lane.
+func (ls *laneState) prf(p *prf, adr *[8]uint32, cs []wotsChain, first uint32, active int, km uint32, out *[lanes][8]uint32) {
+	for l := 0; l < active; l++ {
+		for j := range adr {
+			ls.w[j][l] = adr[j]
+			ls.stat[j][l] = p.block1[j]
+		}
+		ls.w[adrChain/4][l] = first + uint32(ls.cur[l])
+		ls.w[adrHash/4][l] = uint32(cs[ls.cur[l]].start + ls.step[l])
+		ls.w[adrKM/4][l] = km
+	}
+	ls.pad(active)
+	blockLanes(&ls.stat, &ls.w)
+	ls.digest(active, out)
+}
+
+//hashF sets the chain in every lane to F(key, x XOR bm).
+func (ls *laneState) hashF(active int) {
+	for l := 0; l < active; l++ {
+		for j := 0; j < 8; j++ {
+			ls.w[j][l] = 0
+			ls.w[8+j][l] = ls.key[l][j]
+			ls.stat[j][l] = sha256Init[j]
+		}
+	}
+	blockLanes(&ls.stat, &ls.w)
+	for l := 0; l < active; l++ {
+		for j := 0; j < 8; j++ {
+			ls.w[j][l] = ls.x[l][j] ^ ls.bm[l][j]
+		}
+	}
+	ls.pad(active)
+	blockLanes(&ls.stat, &ls.w)
+	ls.digest(active, &ls.x)
+}
+
+//pad sets the second half of the blocks to the padding of a 96 bytes
+//message, the length of the input of F and PRF.
+func (ls *laneState) pad(active int) {
+	for l := 0; l < active; l++ {
+		ls.w[8][l] = 0x80000000
+		for j := 9; j < 15; j++ {
+			ls.w[j][l] = 0
+		}
+		ls.w[15][l] = 96 * 8
+	}
+}
+
+//digest copies the states of the lanes to out.
+func (ls *laneState) digest(active int, out *[lanes][8]uint32) {
+	for l := 0; l < active; l++ {
+		for j := range out[l] {
+			out[l][j] = ls.stat[j][l]
+		}
+	}
+}
+
+var sha256K = [64]uint32{
+	0x428a2f98, 0x71374491, 0xb5c0fbcf, 0xe9b5dba5, 0x3956c25b, 0x59f111f1, 0x923f82a4, 0xab1c5ed5,
+	0xd807aa98, 0x12835b01, 0x243185be, 0x550c7dc3, 0x72be5d74, 0x80deb1fe, 0x9bdc06a7, 0xc19bf174,
+	0xe49b69c1, 0xefbe4786, 0x0fc19dc6, 0x240ca1cc, 0x2de92c6f, 0x4a7484aa, 0x5cb0a9dc, 0x76f988da,
+	0x983e5152, 0xa831c66d, 0xb00327c8, 0xbf597fc7, 0xc6e00bf3, 0xd5a79147, 0x06ca6351, 0x14292967,
+	0x27b70a85, 0x2e1b2138, 0x4d2c6dfc, 0x53380d13, 0x650a7354, 0x766a0abb, 0x81c2c92e, 0x92722c85,
+	0xa2bfe8a1, 0xa81a664b, 0xc24b8b70, 0xc76c51a3, 0xd192e819, 0xd6990624, 0xf40e3585, 0x106aa070,
+	0x19a4c116, 0x1e376c08, 0x2748774c, 0x34b0bcb5, 0x391c0cb3, 0x4ed8aa4a, 0x5b9cca4f, 0x682e6ff3,
+	0x748f82ee, 0x78a5636f, 0x84c87814, 0x8cc70208, 0x90befffa, 0xa4506ceb, 0xbef9a3f7, 0xc67178f2,
+}
+
+//blockLanesGeneric is blockLanes in Go, one lane after another.
+func blockLanesGeneric(stat *[8][lanes]uint32, w *[64][lanes]uint32) {
+	for l := 0; l < lanes; l++ {
+		for i := 16; i < 64; i++ {
+			v1 := w[i-2][l]
+			t1 := bits.RotateLeft32(v1, -17) ^ bits.RotateLeft32(v1, -19) ^ (v1 >> 10)
+			v2 := w[i-15][l]
+			t2 := bits.RotateLeft32(v2, -7) ^ bits.RotateLeft32(v2, -18) ^ (v2 >> 3)
+			w[i][l] = t1 + w[i-7][l] + t2 + w[i-16][l]
+		}
+		a, b, c, d := stat[0][l], stat[1][l], stat[2][l], stat[3][l]
+		e, f, g, h := stat[4][l], stat[5][l], stat[6][l], stat[7][l]
+		for i := 0; i < 64; i++ {
+			t1 := h + (bits.RotateLeft32(e, -6) ^ bits.RotateLeft32(e, -11) ^ bits.RotateLeft32(e, -25)) +
+				((e & f) ^ (^e & g)) + sha256K[i] + w[i][l]
+			t2 := (bits.RotateLeft32(a, -2) ^ bits.RotateLeft32(a, -13) ^ bits.RotateLeft32(a, -22)) +
+				((a & b) ^ (a & c) ^ (b & c))
+			h, g, f, e, d, c, b, a = g, f, e, d+t1, c, b, a, t1+t2
+		}
+		stat[0][l] += a
+		stat[1][l] += b
+		stat[2][l] += c
+		stat[3][l] += d
+		stat[4][l] += e
+		stat[5][l] += f
+		stat[6][l] += g
+		stat[7][l] += h
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2019 Benjamin Schlosser
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:build amd64 && !purego && !noasm
+// +build amd64,!purego,!noasm
+
+package xmss
+
+import "golang.org/x/sys/cpu"
+
+//blockLanesAVX2 is blockLanes with the 8 lanes in the 8 words of the AVX2
+//registers.
+//go:noescape
+func blockLanesAVX2(stat *[8][lanes]uint32, w *[64][lanes]uint32)
+
+func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+
+func init() {
+	if cpu.X86.HasAVX2 {
+		blockLanes = blockLanesAVX2
+		//one lane with the SHA extensions, which sha256-simd uses, is faster
+		//than 8 lanes with AVX2.
+		useLanes = !hasSHA()
+	}
+}
+
+//hasSHA reports whether the CPU supports the SHA extensions, for which
+//golang.org/x/sys/cpu has no flag.
+func hasSHA() bool {
+	maxID, _, _, _ := cpuid(0, 0)
+	if maxID < 7 {
+		return false
+	}
+	_, ebx7, _, _ := cpuid(7, 0)
+	return ebx7&(1<<29) != 0
+}
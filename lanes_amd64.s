@@ -0,0 +1,266 @@
+// Copyright (c) 2019 Benjamin Schlosser
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:build amd64 && !purego && !noasm
+// +build amd64,!purego,!noasm
+
+#include "textflag.h"
+
+// The SHA-256 compression function in 8 lanes at once with AVX2. Every Y
+// register holds one word of the state or of the message schedule for all
+// lanes, so the lanes need no shuffling.
+
+// ROR sets dst to x rotated right by n, using tmp.
+#define ROR(n, x, dst, tmp) \
+	VPSRLD $n, x, dst; \
+	VPSLLD $(32-n), x, tmp; \
+	VPOR   tmp, dst, dst
+
+// ROUND computes round i with the message schedule at DI and the constants
+// at R8. It adds T1 to d and sets h to T1+T2, so that the next round is
+// ROUND with the registers rotated by one.
+#define ROUND(a, b, c, d, e, f, g, h, i) \
+	ROR(6, e, Y8, Y9); \
+	ROR(11, e, Y10, Y9); \
+	VPXOR        Y10, Y8, Y8; \
+	ROR(25, e, Y10, Y9); \
+	VPXOR        Y10, Y8, Y8; \
+	VPAND        f, e, Y9; \
+	VPANDN       g, e, Y10; \
+	VPXOR        Y10, Y9, Y9; \
+	VPADDD       Y9, Y8, Y8; \
+	VPADDD       h, Y8, Y8; \
+	VPBROADCASTD (i*4)(R8), Y9; \
+	VPADDD       Y9, Y8, Y8; \
+	VPADDD       (i*32)(DI), Y8, Y8; \
+	VPADDD       Y8, d, d; \
+	ROR(2, a, Y9, Y10); \
+	ROR(13, a, Y11, Y10); \
+	VPXOR        Y11, Y9, Y9; \
+	ROR(22, a, Y11, Y10); \
+	VPXOR        Y11, Y9, Y9; \
+	VPOR         c, b, Y10; \
+	VPAND        a, Y10, Y10; \
+	VPAND        c, b, Y11; \
+	VPOR         Y11, Y10, Y10; \
+	VPADDD       Y10, Y9, Y9; \
+	VPADDD       Y9, Y8, h
+
+// func blockLanesAVX2(stat *[8][lanes]uint32, w *[64][lanes]uint32)
+TEXT ·blockLanesAVX2(SB), NOSPLIT, $0-16
+	MOVQ stat+0(FP), BX
+	MOVQ w+8(FP), DI
+	LEAQ kLanes<>(SB), R8
+
+	// W[i] = σ1(W[i-2]) + W[i-7] + σ0(W[i-15]) + W[i-16] for i = 16..63
+	LEAQ 512(DI), SI
+	MOVQ $48, CX
+
+schedule:
+	VMOVDQU -64(SI), Y8
+	ROR(17, Y8, Y9, Y10)
+	ROR(19, Y8, Y11, Y10)
+	VPXOR   Y11, Y9, Y9
+	VPSRLD  $10, Y8, Y11
+	VPXOR   Y11, Y9, Y9
+	VMOVDQU -480(SI), Y8
+	ROR(7, Y8, Y12, Y10)
+	ROR(18, Y8, Y11, Y10)
+	VPXOR   Y11, Y12, Y12
+	VPSRLD  $3, Y8, Y11
+	VPXOR   Y11, Y12, Y12
+	VPADDD  Y12, Y9, Y9
+	VPADDD  -224(SI), Y9, Y9
+	VPADDD  -512(SI), Y9, Y9
+	VMOVDQU Y9, (SI)
+	ADDQ    $32, SI
+	DECQ    CX
+	JNZ     schedule
+
+	VMOVDQU 0(BX), Y0
+	VMOVDQU 32(BX), Y1
+	VMOVDQU 64(BX), Y2
+	VMOVDQU 96(BX), Y3
+	VMOVDQU 128(BX), Y4
+	VMOVDQU 160(BX), Y5
+	VMOVDQU 192(BX), Y6
+	VMOVDQU 224(BX), Y7
+
+	ROUND(Y0, Y1, Y2, Y3, Y4, Y5, Y6, Y7, 0)
+	ROUND(Y7, Y0, Y1, Y2, Y3, Y4, Y5, Y6, 1)
+	ROUND(Y6, Y7, Y0, Y1, Y2, Y3, Y4, Y5, 2)
+	ROUND(Y5, Y6, Y7, Y0, Y1, Y2, Y3, Y4, 3)
+	ROUND(Y4, Y5, Y6, Y7, Y0, Y1, Y2, Y3, 4)
+	ROUND(Y3, Y4, Y5, Y6, Y7, Y0, Y1, Y2, 5)
+	ROUND(Y2, Y3, Y4, Y5, Y6, Y7, Y0, Y1, 6)
+	ROUND(Y1, Y2, Y3, Y4, Y5, Y6, Y7, Y0, 7)
+	ROUND(Y0, Y1, Y2, Y3, Y4, Y5, Y6, Y7, 8)
+	ROUND(Y7, Y0, Y1, Y2, Y3, Y4, Y5, Y6, 9)
+	ROUND(Y6, Y7, Y0, Y1, Y2, Y3, Y4, Y5, 10)
+	ROUND(Y5, Y6, Y7, Y0, Y1, Y2, Y3, Y4, 11)
+	ROUND(Y4, Y5, Y6, Y7, Y0, Y1, Y2, Y3, 12)
+	ROUND(Y3, Y4, Y5, Y6, Y7, Y0, Y1, Y2, 13)
+	ROUND(Y2, Y3, Y4, Y5, Y6, Y7, Y0, Y1, 14)
+	ROUND(Y1, Y2, Y3, Y4, Y5, Y6, Y7, Y0, 15)
+	ROUND(Y0, Y1, Y2, Y3, Y4, Y5, Y6, Y7, 16)
+	ROUND(Y7, Y0, Y1, Y2, Y3, Y4, Y5, Y6, 17)
+	ROUND(Y6, Y7, Y0, Y1, Y2, Y3, Y4, Y5, 18)
+	ROUND(Y5, Y6, Y7, Y0, Y1, Y2, Y3, Y4, 19)
+	ROUND(Y4, Y5, Y6, Y7, Y0, Y1, Y2, Y3, 20)
+	ROUND(Y3, Y4, Y5, Y6, Y7, Y0, Y1, Y2, 21)
+	ROUND(Y2, Y3, Y4, Y5, Y6, Y7, Y0, Y1, 22)
+	ROUND(Y1, Y2, Y3, Y4, Y5, Y6, Y7, Y0, 23)
+	ROUND(Y0, Y1, Y2, Y3, Y4, Y5, Y6, Y7, 24)
+	ROUND(Y7, Y0, Y1, Y2, Y3, Y4, Y5, Y6, 25)
+	ROUND(Y6, Y7, Y0, Y1, Y2, Y3, Y4, Y5, 26)
+	ROUND(Y5, Y6, Y7, Y0, Y1, Y2, Y3, Y4, 27)
+	ROUND(Y4, Y5, Y6, Y7, Y0, Y1, Y2, Y3, 28)
+	ROUND(Y3, Y4, Y5, Y6, Y7, Y0, Y1, Y2, 29)
+	ROUND(Y2, Y3, Y4, Y5, Y6, Y7, Y0, Y1, 30)
+	ROUND(Y1, Y2, Y3, Y4, Y5, Y6, Y7, Y0, 31)
+	ROUND(Y0, Y1, Y2, Y3, Y4, Y5, Y6, Y7, 32)
+	ROUND(Y7, Y0, Y1, Y2, Y3, Y4, Y5, Y6, 33)
+	ROUND(Y6, Y7, Y0, Y1, Y2, Y3, Y4, Y5, 34)
+	ROUND(Y5, Y6, Y7, Y0, Y1, Y2, Y3, Y4, 35)
+	ROUND(Y4, Y5, Y6, Y7, Y0, Y1, Y2, Y3, 36)
+	ROUND(Y3, Y4, Y5, Y6, Y7, Y0, Y1, Y2, 37)
+	ROUND(Y2, Y3, Y4, Y5, Y6, Y7, Y0, Y1, 38)
+	ROUND(Y1, Y2, Y3, Y4, Y5, Y6, Y7, Y0, 39)
+	ROUND(Y0, Y1, Y2, Y3, Y4, Y5, Y6, Y7, 40)
+	ROUND(Y7, Y0, Y1, Y2, Y3, Y4, Y5, Y6, 41)
+	ROUND(Y6, Y7, Y0, Y1, Y2, Y3, Y4, Y5, 42)
+	ROUND(Y5, Y6, Y7, Y0, Y1, Y2, Y3, Y4, 43)
+	ROUND(Y4, Y5, Y6, Y7, Y0, Y1, Y2, Y3, 44)
+	ROUND(Y3, Y4, Y5, Y6, Y7, Y0, Y1, Y2, 45)
+	ROUND(Y2, Y3, Y4, Y5, Y6, Y7, Y0, Y1, 46)
+	ROUND(Y1, Y2, Y3, Y4, Y5, Y6, Y7, Y0, 47)
+	ROUND(Y0, Y1, Y2, Y3, Y4, Y5, Y6, Y7, 48)
+	ROUND(Y7, Y0, Y1, Y2, Y3, Y4, Y5, Y6, 49)
+	ROUND(Y6, Y7, Y0, Y1, Y2, Y3, Y4, Y5, 50)
+	ROUND(Y5, Y6, Y7, Y0, Y1, Y2, Y3, Y4, 51)
+	ROUND(Y4, Y5, Y6, Y7, Y0, Y1, Y2, Y3, 52)
+	ROUND(Y3, Y4, Y5, Y6, Y7, Y0, Y1, Y2, 53)
+	ROUND(Y2, Y3, Y4, Y5, Y6, Y7, Y0, Y1, 54)
+	ROUND(Y1, Y2, Y3, Y4, Y5, Y6, Y7, Y0, 55)
+	ROUND(Y0, Y1, Y2, Y3, Y4, Y5, Y6, Y7, 56)
+	ROUND(Y7, Y0, Y1, Y2, Y3, Y4, Y5, Y6, 57)
+	ROUND(Y6, Y7, Y0, Y1, Y2, Y3, Y4, Y5, 58)
+	ROUND(Y5, Y6, Y7, Y0, Y1, Y2, Y3, Y4, 59)
+	ROUND(Y4, Y5, Y6, Y7, Y0, Y1, Y2, Y3, 60)
+	ROUND(Y3, Y4, Y5, Y6, Y7, Y0, Y1, Y2, 61)
+	ROUND(Y2, Y3, Y4, Y5, Y6, Y7, Y0, Y1, 62)
+	ROUND(Y1, Y2, Y3, Y4, Y5, Y6, Y7, Y0, 63)
+
+	VPADDD  0(BX), Y0, Y0
+	VMOVDQU Y0, 0(BX)
+	VPADDD  32(BX), Y1, Y1
+	VMOVDQU Y1, 32(BX)
+	VPADDD  64(BX), Y2, Y2
+	VMOVDQU Y2, 64(BX)
+	VPADDD  96(BX), Y3, Y3
+	VMOVDQU Y3, 96(BX)
+	VPADDD  128(BX), Y4, Y4
+	VMOVDQU Y4, 128(BX)
+	VPADDD  160(BX), Y5, Y5
+	VMOVDQU Y5, 160(BX)
+	VPADDD  192(BX), Y6, Y6
+	VMOVDQU Y6, 192(BX)
+	VPADDD  224(BX), Y7, Y7
+	VMOVDQU Y7, 224(BX)
+
+	VZEROUPPER
+	RET
+
+// func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+TEXT ·cpuid(SB), NOSPLIT, $0-24
+	MOVL eaxArg+0(FP), AX
+	MOVL ecxArg+4(FP), CX
+	CPUID
+	MOVL AX, eax+8(FP)
+	MOVL BX, ebx+12(FP)
+	MOVL CX, ecx+16(FP)
+	MOVL DX, edx+20(FP)
+	RET
+
+DATA kLanes<>+0x00(SB)/4, $0x428a2f98
+DATA kLanes<>+0x04(SB)/4, $0x71374491
+DATA kLanes<>+0x08(SB)/4, $0xb5c0fbcf
+DATA kLanes<>+0x0c(SB)/4, $0xe9b5dba5
+DATA kLanes<>+0x10(SB)/4, $0x3956c25b
+DATA kLanes<>+0x14(SB)/4, $0x59f111f1
+DATA kLanes<>+0x18(SB)/4, $0x923f82a4
+DATA kLanes<>+0x1c(SB)/4, $0xab1c5ed5
+DATA kLanes<>+0x20(SB)/4, $0xd807aa98
+DATA kLanes<>+0x24(SB)/4, $0x12835b01
+DATA kLanes<>+0x28(SB)/4, $0x243185be
+DATA kLanes<>+0x2c(SB)/4, $0x550c7dc3
+DATA kLanes<>+0x30(SB)/4, $0x72be5d74
+DATA kLanes<>+0x34(SB)/4, $0x80deb1fe
+DATA kLanes<>+0x38(SB)/4, $0x9bdc06a7
+DATA kLanes<>+0x3c(SB)/4, $0xc19bf174
+DATA kLanes<>+0x40(SB)/4, $0xe49b69c1
+DATA kLanes<>+0x44(SB)/4, $0xefbe4786
+DATA kLanes<>+0x48(SB)/4, $0x0fc19dc6
+DATA kLanes<>+0x4c(SB)/4, $0x240ca1cc
+DATA kLanes<>+0x50(SB)/4, $0x2de92c6f
+DATA kLanes<>+0x54(SB)/4, $0x4a7484aa
+DATA kLanes<>+0x58(SB)/4, $0x5cb0a9dc
+DATA kLanes<>+0x5c(SB)/4, $0x76f988da
+DATA kLanes<>+0x60(SB)/4, $0x983e5152
+DATA kLanes<>+0x64(SB)/4, $0xa831c66d
+DATA kLanes<>+0x68(SB)/4, $0xb00327c8
+DATA kLanes<>+0x6c(SB)/4, $0xbf597fc7
+DATA kLanes<>+0x70(SB)/4, $0xc6e00bf3
+DATA kLanes<>+0x74(SB)/4, $0xd5a79147
+DATA kLanes<>+0x78(SB)/4, $0x06ca6351
+DATA kLanes<>+0x7c(SB)/4, $0x14292967
+DATA kLanes<>+0x80(SB)/4, $0x27b70a85
+DATA kLanes<>+0x84(SB)/4, $0x2e1b2138
+DATA kLanes<>+0x88(SB)/4, $0x4d2c6dfc
+DATA kLanes<>+0x8c(SB)/4, $0x53380d13
+DATA kLanes<>+0x90(SB)/4, $0x650a7354
+DATA kLanes<>+0x94(SB)/4, $0x766a0abb
+DATA kLanes<>+0x98(SB)/4, $0x81c2c92e
+DATA kLanes<>+0x9c(SB)/4, $0x92722c85
+DATA kLanes<>+0xa0(SB)/4, $0xa2bfe8a1
+DATA kLanes<>+0xa4(SB)/4, $0xa81a664b
+DATA kLanes<>+0xa8(SB)/4, $0xc24b8b70
+DATA kLanes<>+0xac(SB)/4, $0xc76c51a3
+DATA kLanes<>+0xb0(SB)/4, $0xd192e819
+DATA kLanes<>+0xb4(SB)/4, $0xd6990624
+DATA kLanes<>+0xb8(SB)/4, $0xf40e3585
+DATA kLanes<>+0xbc(SB)/4, $0x106aa070
+DATA kLanes<>+0xc0(SB)/4, $0x19a4c116
+DATA kLanes<>+0xc4(SB)/4, $0x1e376c08
+DATA kLanes<>+0xc8(SB)/4, $0x2748774c
+DATA kLanes<>+0xcc(SB)/4, $0x34b0bcb5
+DATA kLanes<>+0xd0(SB)/4, $0x391c0cb3
+DATA kLanes<>+0xd4(SB)/4, $0x4ed8aa4a
+DATA kLanes<>+0xd8(SB)/4, $0x5b9cca4f
+DATA kLanes<>+0xdc(SB)/4, $0x682e6ff3
+DATA kLanes<>+0xe0(SB)/4, $0x748f82ee
+DATA kLanes<>+0xe4(SB)/4, $0x78a5636f
+DATA kLanes<>+0xe8(SB)/4, $0x84c87814
+DATA kLanes<>+0xec(SB)/4, $0x8cc70208
+DATA kLanes<>+0xf0(SB)/4, $0x90befffa
+DATA kLanes<>+0xf4(SB)/4, $0xa4506ceb
+DATA kLanes<>+0xf8(SB)/4, $0xbef9a3f7
+DATA kLanes<>+0xfc(SB)/4, $0xc67178f2
+GLOBL kLanes<>(SB), (NOPTR+RODATA), $256
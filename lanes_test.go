@@ -0,0 +1,146 @@
+// Copyright (c) 2019 Benjamin Schlosser
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	mrand "math/rand"
+	"testing"
+
+	sha256 "github.com/AidosKuneen/sha256-simd"
+)
+
+func TestBlockLanes(t *testing.T) {
+	var stat [8][lanes]uint32
+	var w [64][lanes]uint32
+	var blocks [lanes][64]byte
+	if _, err := rand.Read(blocks[0][:]); err != nil {
+		t.Fatal(err)
+	}
+	var want [lanes][]uint32
+	for l := range blocks {
+		copy(blocks[l][:], blocks[0][l:])
+		want[l] = make([]uint32, 8)
+		for j := range stat {
+			stat[j][l] = sha256Init[j] + uint32(l*j)
+			want[l][j] = stat[j][l]
+		}
+		for j := 0; j < 16; j++ {
+			w[j][l] = binary.BigEndian.Uint32(blocks[l][4*j:])
+		}
+		sha256.Block(want[l], blocks[l][:])
+	}
+	check := func(name string, block func(*[8][lanes]uint32, *[64][lanes]uint32)) {
+		s, ww := stat, w
+		block(&s, &ww)
+		for l := range want {
+			for j := range s {
+				if s[j][l] != want[l][j] {
+					t.Fatalf("%s: word %d of lane %d is incorrect", name, j, l)
+				}
+			}
+		}
+	}
+	check("blockLanesGeneric", blockLanesGeneric)
+	check("blockLanes", blockLanes)
+}
+
+func TestChainLanes(t *testing.T) {
+	p := sha256Func.newPRF(generateSeed())
+	adr := make(addr, 32)
+	if _, err := rand.Read(adr); err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range []int{1, 2, lanes, lanes + 1, 67} {
+		cs := make([]wotsChain, n)
+		want := make([][]byte, n)
+		a := make(addr, 32)
+		copy(a, adr)
+		for i := range cs {
+			cs[i].in = generateSeed()
+			cs[i].out = make([]byte, 32)
+			cs[i].start = byte(mrand.Intn(w))
+			cs[i].steps = byte(mrand.Intn(w - int(cs[i].start)))
+			want[i] = make([]byte, 32)
+			a.set(adrChain, 5+uint32(i))
+			chain(newScratch(), cs[i].in, cs[i].start, cs[i].steps, p, a, want[i])
+		}
+		check := func(name string) {
+			for i := range cs {
+				if !bytes.Equal(cs[i].out, want[i]) {
+					t.Errorf("%s: chain %d of %d is incorrect", name, i, n)
+				}
+				cs[i].out = make([]byte, 32)
+			}
+		}
+		chainLanes(newScratch(), adr, p, cs, 5)
+		check("chainLanes")
+		block := blockLanes
+		blockLanes = blockLanesGeneric
+		chainLanes(newScratch(), adr, p, cs, 5)
+		blockLanes = block
+		check("chainLanes with blockLanesGeneric")
+		seqChain(newScratch(), adr, p, cs, 5)
+		check("seqChain")
+	}
+}
+
+//TestXMSSLanes runs the known-answer vectors of TestXMSS with the chains
+//hashed in lockstep, whether or not the CPU makes them the default.
+func TestXMSSLanes(t *testing.T) {
+	use, block := useLanes, blockLanes
+	defer func() {
+		useLanes, blockLanes = use, block
+	}()
+	useLanes = true
+	t.Run("blockLanes", testXMSSVectors)
+	blockLanes = blockLanesGeneric
+	t.Run("blockLanesGeneric", testXMSSVectors)
+}
+
+func BenchmarkChainLanes(b *testing.B) {
+	benchmarkChains(b, true)
+}
+
+func BenchmarkChainSequential(b *testing.B) {
+	benchmarkChains(b, false)
+}
+
+func benchmarkChains(b *testing.B, lanes bool) {
+	s := newScratch()
+	p := sha256Func.newPRF(generateSeed())
+	adr := make(addr, 32)
+	cs := make([]wotsChain, wlen(32))
+	for i := range cs {
+		cs[i] = wotsChain{in: generateSeed(), out: make([]byte, 32), steps: w - 1}
+	}
+	use := useLanes
+	useLanes = lanes
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		seqChain(s, adr, p, cs, 0)
+	}
+	b.StopTimer()
+	useLanes = use
+}
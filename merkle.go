@@ -21,8 +21,12 @@
 package xmss
 
 import (
-	"math"
-	"runtime"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
 	"sync"
 )
 
@@ -40,105 +44,34 @@ type stack struct {
 	leaf   uint32
 	layer  uint32
 	tree   uint64
+	sc     *scratch // working memory of the goroutine using the stack
 }
 
-func (s *stack) low() uint32 {
-	if len(s.stack) == 0 {
-		return s.height
-	}
-	if s.top().height == s.height {
-		return math.MaxUint32
-	}
-	var min uint32 = math.MaxUint32
-	for _, n := range s.stack {
-		if n.height < min {
-			min = n.height
-		}
-	}
-	return min
-}
-
-func (s *stack) initialize(start uint32, height uint32) {
-	s.leaf = start
-	s.height = height
-	s.stack = s.stack[:0]
-}
-
-func (s *stack) newleaf(priv *PrivateKey, isGo bool) {
-	pk := make(wotsPubKey, wlen)
-	sk := make(wotsPrivKey, wlen)
-	for j := 0; j < wlen; j++ {
-		pk[j] = make([]byte, n)
-		sk[j] = make([]byte, n)
-	}
-	addrs := make(addr, 32)
-
-	// addrs.set(adrType, 0)
-	addrs.set(adrLayer, s.layer)
-	addrs.setTree(s.tree)
-	addrs.set(adrOTS, s.leaf)
-	priv.newWotsPrivKey(addrs, sk)
-	pubPRF := newPRF(priv.publicSeed)
-	if isGo {
-		sk.goNewWotsPubKey(pubPRF, addrs, pk)
-	} else {
-		sk.newWotsPubKey(pubPRF, addrs, pk)
-	}
-	addrs.set(adrType, 1)
-	addrs.set(adrLtree, s.leaf)
-	nn := pk.ltree(pubPRF, addrs)
+//newleaf pushes the next leaf, computing its WOTS+ chains on procs goroutines.
+func (s *stack) newleaf(priv *PrivateKey, procs int) {
 	node := &nh{
-		node:   make([]byte, 32),
+		node:   make([]byte, priv.N),
 		height: 0,
 		index:  s.leaf,
 	}
-	copy(node.node, nn)
+	priv.leafNode(s.sc, s.leaf, s.layer, s.tree, procs, node.node)
 	s.push(node)
 	s.leaf++
 }
 
-func (s *stack) update(nn uint64, priv *PrivateKey) {
-	s.updateSub(nn, priv, func() {
-		s.newleaf(priv, false)
-	})
-}
-
-func (s *stack) goUpdate(nn uint64, priv *PrivateKey) {
-	s.updateSub(nn, priv, func() {
-		s.newleaf(priv, true)
-	})
+//leafNode writes the leaf of the one-time key leaf to out, computing its
+//WOTS+ chains on procs goroutines.
+func (priv *PrivateKey) leafNode(s *scratch, leaf uint32, layer uint32, tree uint64, procs int, out []byte) {
+	sk, pk := s.wots(int(priv.N))
+	addrs := s.otsAddr(layer, tree, leaf)
+	priv.newWotsPrivKey(s, addrs, sk)
+	pubPRF := s.prf(priv.hash(), priv.publicSeed)
+	sk.goNewWotsPubKey(s, pubPRF, addrs, pk, procs)
+	addrs.set(adrType, 1)
+	addrs.set(adrLtree, leaf)
+	copy(out, pk.ltree(s, pubPRF, addrs))
 }
 
-func (s *stack) updateSub(nn uint64, priv *PrivateKey, newleaf func()) {
-	if len(s.stack) > 0 && (s.stack[len(s.stack)-1].height == s.height) {
-		return
-	}
-	addrs := make(addr, 32)
-	addrs.set(adrType, 2)
-	addrs.set(adrLayer, s.layer)
-	addrs.setTree(s.tree)
-	pubPRF := newPRF(priv.publicSeed)
-	for i := uint64(0); i < nn; i++ {
-		if len(s.stack) >= 2 {
-			right := s.top()
-			left := s.nextTop()
-			if left.height == right.height {
-				node := &nh{
-					node: make([]byte, 32),
-				}
-				node.index = right.index >> 1
-				node.height = right.height + 1
-				addrs.set(adrHeight, right.height)
-				addrs.set(adrIndex, node.index)
-				randHash(left.node, right.node, pubPRF, addrs, node.node)
-				s.delete(2)
-				s.push(node)
-				continue
-			}
-		}
-		newleaf()
-	}
-}
 func (s *stack) top() *nh {
 	return s.stack[len(s.stack)-1]
 }
@@ -155,119 +88,565 @@ func (s *stack) delete(i int) {
 	s.stack = s.stack[:len(s.stack)-i]
 }
 
-//merkle represents MerkleTree for XMSS.
+//merkle represents MerkleTree for XMSS, with the state of the BDS
+//traversal algorithm. Its nodes are owned by it and overwritten in place,
+//so that the traversal does not allocate.
 type merkle struct {
 	//leaf is the number of unused leaf.
-	leaf   uint32
-	height uint32
-	stacks []*stack
-	auth   [][]byte
-	layer  uint32
-	tree   uint64
+	leaf     uint32
+	height   uint32
+	k        uint32 // number of top levels whose right nodes are retained
+	auth     [][]byte
+	keep     [][]byte    // nodes on the path to the leaf, one for two levels
+	retain   [][]byte    // right nodes of the top k levels
+	treehash []*treehash // one instance for each of the lower height-k levels
+	stack    []*nh       // stack shared by the treehash instances
+	layer    uint32
+	tree     uint64
+}
+
+//treehash is an instance of the treehash algorithm, which computes the
+//next right auth node of its height using the shared stack.
+type treehash struct {
+	node      []byte
+	height    uint32
+	next      uint32 // next leaf to compute
+	usage     uint32 // number of nodes on the shared stack
+	completed bool
+}
+
+//defaultBDSK returns the smallest BDS parameter k which is valid for height.
+func defaultBDSK(height uint32) uint32 {
+	return height & 1
+}
+
+//validateBDSK checks the BDS parameter k for height.
+func validateBDSK(height, k uint32) error {
+	if k > height || (height-k)&1 != 0 {
+		return fmt.Errorf("xmss: BDS parameter k = %d is invalid for height %d, height - k must be even and not negative", k, height)
+	}
+	return nil
+}
+
+//hashNodes writes the parent of left and right at height to out, where index
+//is the index of the parent. out may be left or right.
+func (priv *PrivateKey) hashNodes(s *scratch, left, right []byte, height, index uint32, layer uint32, tree uint64, out []byte) {
+	addrs := addr(s.adr[:])
+	copy(addrs, zero64)
+	addrs.set(adrType, 2)
+	addrs.set(adrLayer, layer)
+	addrs.setTree(tree)
+	addrs.set(adrHeight, height)
+	addrs.set(adrIndex, index)
+	randHash(s, left, right, s.prf(priv.hash(), priv.publicSeed), addrs, out)
+}
+
+//round updates the auth path for the leaf after m.leaf and restarts the
+//treehash instances whose nodes are used up, as bds_round of the reference
+//implementation.
+func (priv *PrivateKey) round() {
+	m := priv.m
+	h := m.height
+	leaf := m.leaf
+	tau := h
+	for i := uint32(0); i < h; i++ {
+		if (leaf>>i)&0x1 == 0 {
+			tau = i
+			break
+		}
+	}
+	s := priv.scratch()
+	//right is kept before keep is overwritten.
+	right := s.node[:priv.N]
+	if tau > 0 {
+		copy(right, m.keep[(tau-1)>>1])
+	}
+	if (leaf>>(tau+1))&0x1 == 0 && tau < h-1 {
+		copy(m.keep[tau>>1], m.auth[tau])
+	}
+	if tau == 0 {
+		priv.leafNode(s, leaf, m.layer, m.tree, priv.concurrency(), m.auth[0])
+		return
+	}
+	priv.hashNodes(s, m.auth[tau-1], right, tau-1, leaf>>tau, m.layer, m.tree, m.auth[tau])
+	for i := uint32(0); i < tau; i++ {
+		if i < h-m.k {
+			copy(m.auth[i], m.treehash[i].node)
+			continue
+		}
+		offset := (1 << (h - 1 - i)) + int(i) - int(h)
+		row := int(((leaf >> i) - 1) >> 1)
+		copy(m.auth[i], m.retain[offset+row])
+	}
+	for i := uint32(0); i < tau && i < h-m.k; i++ {
+		start := uint64(leaf) + 1 + 3<<i
+		if start < 1<<h {
+			th := m.treehash[i]
+			th.next = uint32(start)
+			th.usage = 0
+			th.completed = false
+		}
+	}
+}
+
+//treehashUpdate spends up to n leaf computations on the treehash instances,
+//always updating the one with the lowest node on the shared stack.
+func (priv *PrivateKey) treehashUpdate(n uint32) {
+	m := priv.m
+	for j := uint32(0); j < n; j++ {
+		level := m.height - m.k
+		min := m.height
+		for i, th := range m.treehash {
+			var low uint32
+			switch {
+			case th.completed:
+				low = m.height
+			case th.usage == 0:
+				low = uint32(i)
+			default:
+				low = m.minHeight(th)
+			}
+			if low < min {
+				level = uint32(i)
+				min = low
+			}
+		}
+		if level == m.height-m.k {
+			return
+		}
+		priv.treehashStep(m.treehash[level])
+	}
+}
+
+//minHeight returns the lowest height of the nodes of th on the shared stack.
+func (m *merkle) minHeight(th *treehash) uint32 {
+	min := m.height
+	for _, n := range m.stack[len(m.stack)-int(th.usage):] {
+		if n.height < min {
+			min = n.height
+		}
+	}
+	return min
+}
+
+//treehashStep computes the next leaf of th and merges it with the nodes of
+//th on the shared stack.
+func (priv *PrivateKey) treehashStep(th *treehash) {
+	m := priv.m
+	s := priv.scratch()
+	node := s.node[:priv.N]
+	priv.leafNode(s, th.next, m.layer, m.tree, priv.concurrency(), node)
+	height, index := uint32(0), th.next
+	for th.usage > 0 && m.stack[len(m.stack)-1].height == height {
+		top := m.stack[len(m.stack)-1]
+		priv.hashNodes(s, top.node, node, height, index>>1, m.layer, m.tree, top.node)
+		node = top.node
+		height++
+		index >>= 1
+		m.stack = m.stack[:len(m.stack)-1]
+		th.usage--
+	}
+	if height == th.height {
+		copy(th.node, node)
+		th.completed = true
+	} else {
+		top := m.push(priv.N)
+		copy(top.node, node)
+		top.height, top.index = height, index
+		th.usage++
+	}
+	th.next++
+}
+
+//push pushes a node of n bytes onto the shared stack and returns it. The
+//nodes popped from the stack are reused, so node may be the last one
+//popped.
+func (m *merkle) push(n uint32) *nh {
+	if len(m.stack) == cap(m.stack) {
+		m.stack = append(m.stack, nil)
+	} else {
+		m.stack = m.stack[:len(m.stack)+1]
+	}
+	top := m.stack[len(m.stack)-1]
+	if top == nil {
+		top = &nh{node: make([]byte, n)}
+		m.stack[len(m.stack)-1] = top
+	}
+	return top
+}
+
+//traverse refreshes auth and treehash instances and increment leaf number.
+func (priv *PrivateKey) traverse() {
+	m := priv.m
+	if uint64(m.leaf)+1 < 1<<m.height {
+		priv.round()
+		priv.treehashUpdate((m.height - m.k) >> 1)
+	}
+	m.leaf++
 }
 
-func (priv *PrivateKey) initMerkle(h uint32, layer uint32, tree uint64) {
+//initMerkleAt initializes the traversal state at leaf directly, without
+//walking from leaf 0, and returns the root. Every treehash instance holds
+//the completed node which becomes the auth node of its height next, so the
+//state needs a single pass over the tree, which runs on the goroutines of
+//the key.
+func (priv *PrivateKey) initMerkleAt(leaf uint32, layer uint32, tree uint64) []byte {
+	root, _ := priv.initMerkleAtContext(context.Background(), leaf, layer, tree, nil)
+	return root
+}
+
+//initMerkleAtContext is initMerkleAt which stops with the error of ctx when
+//ctx is done and calls progress, if not nil, after every computed leaf.
+func (priv *PrivateKey) initMerkleAtContext(ctx context.Context, leaf uint32, layer uint32, tree uint64, progress func(done, total uint64)) ([]byte, error) {
+	h, k := priv.Height, priv.bdsK
 	m := &merkle{
-		leaf:   0,
-		height: h,
-		stacks: make([]*stack, h),
-		auth:   make([][]byte, h),
-		layer:  layer,
-		tree:   tree,
+		leaf:     leaf,
+		height:   h,
+		k:        k,
+		auth:     make([][]byte, h),
+		keep:     make([][]byte, h>>1),
+		retain:   make([][]byte, (1<<k)-k-1),
+		treehash: make([]*treehash, h-k),
+		stack:    make([]*nh, 0, h+1),
+		layer:    layer,
+		tree:     tree,
+	}
+	for i := range m.auth {
+		m.auth[i] = make([]byte, priv.N)
+	}
+	for i := range m.keep {
+		m.keep[i] = make([]byte, priv.N)
+	}
+	for i := range m.retain {
+		m.retain[i] = make([]byte, priv.N)
+	}
+	for i := range m.treehash {
+		m.treehash[i] = &treehash{
+			node:      make([]byte, priv.N),
+			height:    uint32(i),
+			completed: true,
+		}
+	}
+	root, err := priv.walkTree(ctx, layer, tree, func(n *nh) {
+		i, a := n.height, n.index
+		if i >= h {
+			return
+		}
+		s := leaf >> i
+		if a == s^1 {
+			copy(m.auth[i], n.node)
+		}
+		if i < h-k && a == (s+2)|1 {
+			copy(m.treehash[i].node, n.node)
+		}
+		if i < h-1 && s&0x1 == 1 && (s>>1)&0x1 == 0 && a == s {
+			copy(m.keep[i>>1], n.node)
+		}
+		if i >= h-k && i < h-1 && a&0x1 == 1 && a >= 3 {
+			offset := (1 << (h - 1 - i)) + int(i) - int(h)
+			copy(m.retain[offset+int((a-3)>>1)], n.node)
+		}
+	}, progress)
+	if err != nil {
+		return nil, err
 	}
+	priv.m = m
+	return root, nil
+}
 
-	var wg sync.WaitGroup
-	ncpu := runtime.GOMAXPROCS(-1)
-	nproc := uint32(math.Log2(float64(ncpu)))
-	if ncpu != (1 << nproc) {
-		nproc++
+//walkTree computes all nodes of the tree by treehash, calls visit for every
+//node and returns the root. The subtrees below the top levels are computed
+//in parallel on the goroutines of the key, so visit may be called
+//concurrently for different nodes.
+//progress is called serially with the number of computed leaves.
+//walkTree stops with the error of ctx when ctx is done.
+func (priv *PrivateKey) walkTree(ctx context.Context, layer uint32, tree uint64, visit func(n *nh), progress func(done, total uint64)) ([]byte, error) {
+	h := priv.Height
+	var mu sync.Mutex
+	var done uint64
+	report := func() {
+		if progress == nil {
+			return
+		}
+		mu.Lock()
+		done++
+		progress(done, 1<<h)
+		mu.Unlock()
 	}
+	procs := priv.concurrency()
+	nproc := logProcs(procs)
 	if h <= nproc {
 		nproc = 0
 	}
-	ntop := make([]*nh, (1<<nproc)-1)
-	for i := uint32(1); i < (1 << nproc); i++ {
-		wg.Add(1)
-		go func(i uint32) {
-			s := stack{
-				stack:  make([]*nh, 0, (h-nproc)+1),
-				height: h - nproc,
-				leaf:   (1 << (h - nproc)) * i,
-				layer:  m.layer,
-				tree:   m.tree,
-			}
-			s.update(1<<(h-nproc+1)-1, priv)
-			ntop[i-1] = s.top()
-			wg.Done()
-		}(i)
-	}
-	s := stack{
-		stack:  make([]*nh, 0, h+1),
-		height: h,
-		leaf:   0,
-		layer:  m.layer,
-		tree:   m.tree,
+	leafProcs := 1
+	if nproc == 0 {
+		leafProcs = procs
 	}
-	for i := uint32(0); i < h; i++ {
-		if i == h-nproc {
-			wg.Wait()
+	sub := h - nproc
+	tops := make([]*nh, 1<<nproc)
+	var wg sync.WaitGroup
+	walk := func(j int) {
+		defer wg.Done()
+		s := stack{
+			stack:  make([]*nh, 0, sub+1),
+			height: sub,
+			leaf:   uint32(j) << sub,
+			layer:  layer,
+			tree:   tree,
+			sc:     newScratch(),
 		}
-		s.update(1, priv)
-		m.stacks[i] = &stack{
-			stack:  make([]*nh, 0, i+1),
-			height: i,
-			leaf:   1 << i,
-			layer:  m.layer,
-			tree:   m.tree,
+		for i := 0; i < 1<<sub; i++ {
+			if ctx.Err() != nil {
+				return
+			}
+			s.newleaf(priv, leafProcs)
+			report()
+			visit(s.top())
+			for len(s.stack) >= 2 && s.top().height == s.nextTop().height {
+				left, right := s.nextTop(), s.top()
+				node := &nh{
+					node:   make([]byte, priv.N),
+					height: right.height + 1,
+					index:  right.index >> 1,
+				}
+				priv.hashNodes(s.sc, left.node, right.node, right.height, right.index>>1, layer, tree, node.node)
+				s.delete(2)
+				s.push(node)
+				visit(node)
+			}
 		}
-		m.stacks[i].push(s.top())
-		if i < h-nproc {
-			s.update(1<<(i+1)-1, priv)
-		} else {
-			s.updateSub(1<<(i-(h-nproc)+1)-1, priv, func() {
-				n := ntop[0]
-				ntop = ntop[1:]
-				s.push(n)
-			})
+		tops[j] = s.top()
+	}
+	wg.Add(len(tops))
+	if len(tops) == 1 {
+		walk(0)
+	} else {
+		for j := range tops {
+			go walk(j)
 		}
-		m.auth[i] = make([]byte, 32)
-		copy(m.auth[i], s.top().node)
 	}
-	s.update(1, priv)
-	copy(priv.root, s.top().node)
-	priv.m = m
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	for len(tops) > 1 {
+		for j := 0; j < len(tops)/2; j++ {
+			left, right := tops[2*j], tops[2*j+1]
+			node := &nh{
+				node:   make([]byte, priv.N),
+				height: right.height + 1,
+				index:  right.index >> 1,
+			}
+			priv.hashNodes(priv.scratch(), left.node, right.node, right.height, right.index>>1, layer, tree, node.node)
+			tops[j] = node
+			visit(tops[j])
+		}
+		tops = tops[:len(tops)/2]
+	}
+	return tops[0].node, nil
+}
+
+//skipTo advances the traversal state to index, by traversing for short
+//distances and by rebuilding the state at index otherwise.
+func (priv *PrivateKey) skipTo(index uint32) {
+	m := priv.m
+	if index <= m.leaf {
+		return
+	}
+	if uint64(index) >= 1<<m.height {
+		//all one-time keys are skipped, so there is no state to advance.
+		m.leaf = index
+		return
+	}
+	if uint64(index-m.leaf)*uint64((m.height-m.k)/2+1) > 1<<m.height {
+		priv.initMerkleAt(index, m.layer, m.tree)
+		return
+	}
+	for m.leaf < index {
+		priv.traverse()
+	}
 }
 
-func (m *merkle) refreshAuth() {
-	var h uint32
-	for h = 0; h < m.height; h++ {
-		var pow uint32 = 1 << h
-		if (m.leaf+1)&(pow-1) == 0 {
-			m.auth[h] = m.stacks[h].top().node
-			startnode := ((m.leaf + 1) + pow) ^ pow
-			m.stacks[h].initialize(startnode, h)
+//logProcs returns log2 of procs, rounded down so that at most procs
+//goroutines are used.
+func logProcs(procs int) uint32 {
+	nproc := uint32(0)
+	for 2<<nproc <= procs {
+		nproc++
+	}
+	return nproc
+}
+
+//bdsMagic and bdsVersion identify the encoding of the traversal state.
+var bdsMagic = []byte("XBDS")
+
+//bdsVersion 1 held one treehash stack for each height. It is rebuilt on import.
+const bdsVersion = 2
+
+var (
+	errBDSFormat    = errors.New("xmss: traversal state has an unknown format")
+	errBDSCorrupted = errors.New("xmss: traversal state is corrupted")
+)
+
+//marshalBDS encodes the traversal state as
+//magic || version || n || height || k || layer || tree || leaf || auth || keep || retain ||
+//treehash || number of nodes || (height || index || node)* || SHA-256 checksum,
+//where every treehash instance is next || usage || completed || node.
+func (m *merkle) marshalBDS(n uint32) []byte {
+	nodes := len(m.auth) + len(m.keep) + len(m.retain)
+	size := 37 + nodes*int(n) + len(m.treehash)*(9+int(n)) + len(m.stack)*(8+int(n)) + sha256.Size
+	b := make([]byte, size)
+	copy(b, bdsMagic)
+	b[4] = bdsVersion
+	binary.BigEndian.PutUint32(b[5:], n)
+	binary.BigEndian.PutUint32(b[9:], m.height)
+	binary.BigEndian.PutUint32(b[13:], m.k)
+	binary.BigEndian.PutUint32(b[17:], m.layer)
+	binary.BigEndian.PutUint64(b[21:], m.tree)
+	binary.BigEndian.PutUint32(b[29:], m.leaf)
+	i := 33
+	for _, a := range m.auth {
+		i += copy(b[i:], a)
+	}
+	for _, a := range m.keep {
+		i += copy(b[i:], a)
+	}
+	for _, a := range m.retain {
+		i += copy(b[i:], a)
+	}
+	for _, th := range m.treehash {
+		binary.BigEndian.PutUint32(b[i:], th.next)
+		binary.BigEndian.PutUint32(b[i+4:], th.usage)
+		if th.completed {
+			b[i+8] = 1
 		}
+		i += 9 + copy(b[i+9:], th.node)
 	}
+	binary.BigEndian.PutUint32(b[i:], uint32(len(m.stack)))
+	i += 4
+	for _, node := range m.stack {
+		binary.BigEndian.PutUint32(b[i:], node.height)
+		binary.BigEndian.PutUint32(b[i+4:], node.index)
+		i += 8 + copy(b[i+8:], node.node)
+	}
+	sum := sha256.Sum256(b[:i])
+	copy(b[i:], sum[:])
+	return b
 }
-func (priv *PrivateKey) build() {
-	var i uint32
-	for i = 0; i < ((2 * priv.m.height) - 1); i++ {
-		var min uint32 = math.MaxUint32
-		var h, focus uint32
-		for h = 0; h < priv.m.height; h++ {
-			low := priv.m.stacks[h].low()
-			if low < min {
-				min = low
-				focus = h
+
+//unmarshalBDS decodes the traversal state encoded by marshalBDS.
+//It returns errBDSFormat if b is not in this format at all,
+//e.g. for the state written by BouncyCastle, or of an older version.
+func (priv *PrivateKey) unmarshalBDS(b []byte) (*merkle, error) {
+	if len(b) < len(bdsMagic)+1 || !bytes.Equal(b[:len(bdsMagic)], bdsMagic) {
+		return nil, errBDSFormat
+	}
+	switch v := b[len(bdsMagic)]; {
+	case v < bdsVersion:
+		return nil, errBDSFormat
+	case v > bdsVersion:
+		return nil, fmt.Errorf("xmss: unsupported version %d of traversal state", v)
+	}
+	if len(b) < 37+sha256.Size {
+		return nil, errBDSCorrupted
+	}
+	body, sum := b[:len(b)-sha256.Size], b[len(b)-sha256.Size:]
+	if s := sha256.Sum256(body); !bytes.Equal(s[:], sum) {
+		return nil, errBDSCorrupted
+	}
+	n := binary.BigEndian.Uint32(body[5:])
+	m := &merkle{
+		height: binary.BigEndian.Uint32(body[9:]),
+		k:      binary.BigEndian.Uint32(body[13:]),
+		layer:  binary.BigEndian.Uint32(body[17:]),
+		tree:   binary.BigEndian.Uint64(body[21:]),
+		leaf:   binary.BigEndian.Uint32(body[29:]),
+	}
+	if n != priv.N || m.height != priv.Height {
+		return nil, errors.New("xmss: traversal state does not match the parameters")
+	}
+	if err := validateBDSK(m.height, m.k); err != nil {
+		return nil, err
+	}
+	r := body[33:]
+	next := func(l int) []byte {
+		if len(r) < l {
+			return nil
+		}
+		v := append([]byte(nil), r[:l]...)
+		r = r[l:]
+		return v
+	}
+	nodes := func(count int) ([][]byte, error) {
+		v := make([][]byte, count)
+		for i := range v {
+			if v[i] = next(int(n)); v[i] == nil {
+				return nil, errBDSCorrupted
 			}
 		}
-		priv.m.stacks[focus].goUpdate(1, priv)
+		return v, nil
 	}
+	var err error
+	if m.auth, err = nodes(int(m.height)); err != nil {
+		return nil, err
+	}
+	if m.keep, err = nodes(int(m.height >> 1)); err != nil {
+		return nil, err
+	}
+	if m.retain, err = nodes(1<<m.k - int(m.k) - 1); err != nil {
+		return nil, err
+	}
+	m.treehash = make([]*treehash, m.height-m.k)
+	usage := 0
+	for i := range m.treehash {
+		v := next(9 + int(n))
+		if v == nil || v[8] > 1 {
+			return nil, errBDSCorrupted
+		}
+		m.treehash[i] = &treehash{
+			node:      v[9:],
+			height:    uint32(i),
+			next:      binary.BigEndian.Uint32(v),
+			usage:     binary.BigEndian.Uint32(v[4:]),
+			completed: v[8] == 1,
+		}
+		usage += int(m.treehash[i].usage)
+	}
+	hdr := next(4)
+	if hdr == nil || binary.BigEndian.Uint32(hdr) != uint32(usage) || usage > int(m.height)+1 {
+		return nil, errBDSCorrupted
+	}
+	m.stack = make([]*nh, usage, m.height+1)
+	for i := range m.stack {
+		v := next(8 + int(n))
+		if v == nil {
+			return nil, errBDSCorrupted
+		}
+		m.stack[i] = &nh{
+			height: binary.BigEndian.Uint32(v),
+			index:  binary.BigEndian.Uint32(v[4:]),
+			node:   v[8:],
+		}
+	}
+	if len(r) != 0 || !priv.checkAuth(m) {
+		return nil, errBDSCorrupted
+	}
+	return m, nil
 }
 
-//traverse refreshes auth and stacks and increment leaf number.
-func (priv *PrivateKey) traverse() {
-	priv.m.refreshAuth()
-	priv.build()
-	priv.m.leaf++
+//checkAuth reports whether the auth path of m leads from the leaf m.leaf
+//to the root of priv. It costs the computation of one leaf.
+func (priv *PrivateKey) checkAuth(m *merkle) bool {
+	if uint64(m.leaf) >= 1<<m.height {
+		return true
+	}
+	s := priv.scratch()
+	node := s.node[:priv.N]
+	priv.leafNode(s, m.leaf, m.layer, m.tree, priv.concurrency(), node)
+	addrs := make(addr, 32)
+	addrs.set(adrLayer, m.layer)
+	addrs.setTree(m.tree)
+	addrs.set(adrType, 2)
+	node = rootFromAuth(s, m.leaf, node, m.auth, s.prf(priv.hash(), priv.publicSeed), addrs)
+	return bytes.Equal(node, priv.root)
 }
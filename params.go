@@ -0,0 +1,301 @@
+// Copyright (c) 2019 Benjamin Schlosser
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import (
+	"errors"
+	"fmt"
+)
+
+// HashFunc is the hash function family of a parameter set.
+type HashFunc uint32
+
+const (
+	SHA2 HashFunc = iota
+	SHAKE
+)
+
+func (f HashFunc) String() string {
+	switch f {
+	case SHA2:
+		return "SHA2"
+	case SHAKE:
+		return "SHAKE"
+	}
+	return fmt.Sprintf("HashFunc(%d)", uint32(f))
+}
+
+// XMSS parameters. OID is the RFC 8391 algorithm identifier, or 0 for
+// a height that is not part of a standardized parameter set.
+type XMSSParameters struct {
+	OID    uint32
+	Func   HashFunc // hash function family
+	N      uint32   // length of a hash value in bytes
+	Height uint32   // height of the tree
+}
+
+// XMSS^MT parameters. OID is the RFC 8391 algorithm identifier, or 0 for
+// a combination of height and layers that is not standardized.
+type XMSSMTParameters struct {
+	OID    uint32
+	Func   HashFunc // hash function family
+	N      uint32   // length of a hash value in bytes
+	Height uint32   // total height of the hypertree
+	Layers uint32   // number of XMSS tree layers
+}
+
+var errUnknownOID = errors.New("xmss: unknown parameter set")
+
+// xmssParameterSets contains all XMSS parameter sets of RFC 8391, keyed by OID.
+var xmssParameterSets = map[uint32]XMSSParameters{
+	0x00000001: {0x00000001, SHA2, 32, 10},
+	0x00000002: {0x00000002, SHA2, 32, 16},
+	0x00000003: {0x00000003, SHA2, 32, 20},
+	0x00000004: {0x00000004, SHA2, 64, 10},
+	0x00000005: {0x00000005, SHA2, 64, 16},
+	0x00000006: {0x00000006, SHA2, 64, 20},
+	0x00000007: {0x00000007, SHAKE, 32, 10},
+	0x00000008: {0x00000008, SHAKE, 32, 16},
+	0x00000009: {0x00000009, SHAKE, 32, 20},
+	0x0000000a: {0x0000000a, SHAKE, 64, 10},
+	0x0000000b: {0x0000000b, SHAKE, 64, 16},
+	0x0000000c: {0x0000000c, SHAKE, 64, 20},
+}
+
+// xmssmtParameterSets contains all XMSS^MT parameter sets of RFC 8391, keyed by OID.
+var xmssmtParameterSets = map[uint32]XMSSMTParameters{
+	0x00000001: {0x00000001, SHA2, 32, 20, 2},
+	0x00000002: {0x00000002, SHA2, 32, 20, 4},
+	0x00000003: {0x00000003, SHA2, 32, 40, 2},
+	0x00000004: {0x00000004, SHA2, 32, 40, 4},
+	0x00000005: {0x00000005, SHA2, 32, 40, 8},
+	0x00000006: {0x00000006, SHA2, 32, 60, 3},
+	0x00000007: {0x00000007, SHA2, 32, 60, 6},
+	0x00000008: {0x00000008, SHA2, 32, 60, 12},
+	0x00000009: {0x00000009, SHA2, 64, 20, 2},
+	0x0000000a: {0x0000000a, SHA2, 64, 20, 4},
+	0x0000000b: {0x0000000b, SHA2, 64, 40, 2},
+	0x0000000c: {0x0000000c, SHA2, 64, 40, 4},
+	0x0000000d: {0x0000000d, SHA2, 64, 40, 8},
+	0x0000000e: {0x0000000e, SHA2, 64, 60, 3},
+	0x0000000f: {0x0000000f, SHA2, 64, 60, 6},
+	0x00000010: {0x00000010, SHA2, 64, 60, 12},
+	0x00000011: {0x00000011, SHAKE, 32, 20, 2},
+	0x00000012: {0x00000012, SHAKE, 32, 20, 4},
+	0x00000013: {0x00000013, SHAKE, 32, 40, 2},
+	0x00000014: {0x00000014, SHAKE, 32, 40, 4},
+	0x00000015: {0x00000015, SHAKE, 32, 40, 8},
+	0x00000016: {0x00000016, SHAKE, 32, 60, 3},
+	0x00000017: {0x00000017, SHAKE, 32, 60, 6},
+	0x00000018: {0x00000018, SHAKE, 32, 60, 12},
+	0x00000019: {0x00000019, SHAKE, 64, 20, 2},
+	0x0000001a: {0x0000001a, SHAKE, 64, 20, 4},
+	0x0000001b: {0x0000001b, SHAKE, 64, 40, 2},
+	0x0000001c: {0x0000001c, SHAKE, 64, 40, 4},
+	0x0000001d: {0x0000001d, SHAKE, 64, 40, 8},
+	0x0000001e: {0x0000001e, SHAKE, 64, 60, 3},
+	0x0000001f: {0x0000001f, SHAKE, 64, 60, 6},
+	0x00000020: {0x00000020, SHAKE, 64, 60, 12},
+}
+
+// XMSSParametersByOID returns the XMSS parameter set registered for oid.
+func XMSSParametersByOID(oid uint32) (*XMSSParameters, error) {
+	params, ok := xmssParameterSets[oid]
+	if !ok {
+		return nil, fmt.Errorf("%s: XMSS OID 0x%08x", errUnknownOID, oid)
+	}
+	return &params, nil
+}
+
+// XMSSParametersByName returns the XMSS parameter set with the given
+// RFC 8391 name, e.g. "XMSS-SHA2_10_256".
+func XMSSParametersByName(name string) (*XMSSParameters, error) {
+	for _, params := range xmssParameterSets {
+		if params.String() == name {
+			return &params, nil
+		}
+	}
+	return nil, fmt.Errorf("%s: %q", errUnknownOID, name)
+}
+
+// XMSSMTParametersByOID returns the XMSS^MT parameter set registered for oid.
+func XMSSMTParametersByOID(oid uint32) (*XMSSMTParameters, error) {
+	params, ok := xmssmtParameterSets[oid]
+	if !ok {
+		return nil, fmt.Errorf("%s: XMSS^MT OID 0x%08x", errUnknownOID, oid)
+	}
+	return &params, nil
+}
+
+// XMSSMTParametersByName returns the XMSS^MT parameter set with the given
+// RFC 8391 name, e.g. "XMSSMT-SHA2_20/2_256".
+func XMSSMTParametersByName(name string) (*XMSSMTParameters, error) {
+	for _, params := range xmssmtParameterSets {
+		if params.String() == name {
+			return &params, nil
+		}
+	}
+	return nil, fmt.Errorf("%s: %q", errUnknownOID, name)
+}
+
+// defaultXMSSParameters returns the SHA2 parameters with n = 32 for height,
+// including the OID if the height belongs to a standardized set.
+func defaultXMSSParameters(height uint32) XMSSParameters {
+	return newXMSSParameters(SHA2, 32, height)
+}
+
+// newXMSSParameters returns the parameters for f, n and height, including
+// the OID if they form a standardized set.
+func newXMSSParameters(f HashFunc, n, height uint32) XMSSParameters {
+	params := XMSSParameters{Func: f, N: n, Height: height}
+	for _, p := range xmssParameterSets {
+		if p.Func == params.Func && p.N == params.N && p.Height == params.Height {
+			params.OID = p.OID
+		}
+	}
+	return params
+}
+
+// defaultXMSSMTParameters is the XMSS^MT counterpart of defaultXMSSParameters.
+func defaultXMSSMTParameters(height, layers uint32) XMSSMTParameters {
+	return newXMSSMTParameters(SHA2, 32, height, layers)
+}
+
+// newXMSSMTParameters is the XMSS^MT counterpart of newXMSSParameters.
+func newXMSSMTParameters(f HashFunc, n, height, layers uint32) XMSSMTParameters {
+	params := XMSSMTParameters{Func: f, N: n, Height: height, Layers: layers}
+	for _, p := range xmssmtParameterSets {
+		if p.Func == params.Func && p.N == params.N && p.Height == params.Height && p.Layers == params.Layers {
+			params.OID = p.OID
+		}
+	}
+	return params
+}
+
+func (params XMSSParameters) String() string {
+	return fmt.Sprintf("XMSS-%s_%d_%d", params.Func, params.Height, params.N*8)
+}
+
+func (params XMSSMTParameters) String() string {
+	return fmt.Sprintf("XMSSMT-%s_%d/%d_%d", params.Func, params.Height, params.Layers, params.N*8)
+}
+
+// supported reports whether hash function family and output length are
+// implemented.
+func supported(f HashFunc, n uint32) error {
+	if hashFor(f, n) == nil {
+		return fmt.Errorf("xmss: unsupported hash function %s with n = %d", f, n)
+	}
+	return nil
+}
+
+func (params *XMSSParameters) validate() error {
+	if err := supported(params.Func, params.N); err != nil {
+		return err
+	}
+	//the index after the last one-time key must fit in the uint32 leaf.
+	if params.Height == 0 || params.Height > 31 {
+		return errors.New("xmss: height must be between 1 and 31")
+	}
+	if params.OID != 0 {
+		p, ok := xmssParameterSets[params.OID]
+		if !ok || p != *params {
+			return fmt.Errorf("xmss: parameters do not match OID 0x%08x", params.OID)
+		}
+	}
+	return nil
+}
+
+//validateSeeds returns an error if any of seeds, which are seeds or roots,
+//is not n bytes long.
+func validateSeeds(n uint32, seeds ...[]byte) error {
+	for _, s := range seeds {
+		if len(s) != int(n) {
+			return fmt.Errorf("xmss: seeds and roots must be %d bytes long", n)
+		}
+	}
+	return nil
+}
+
+func (params *XMSSMTParameters) validate() error {
+	if err := supported(params.Func, params.N); err != nil {
+		return err
+	}
+	if params.Height == 0 || params.Layers == 0 || params.Height%params.Layers != 0 {
+		return errors.New("xmss: height must be a non-zero multiple of layers")
+	}
+	if params.Height > 64 || params.Height/params.Layers > 32 {
+		return errors.New("xmss: height of XMSS^MT is too large")
+	}
+	if params.OID != 0 {
+		p, ok := xmssmtParameterSets[params.OID]
+		if !ok || p != *params {
+			return fmt.Errorf("xmss: parameters do not match OID 0x%08x", params.OID)
+		}
+	}
+	return nil
+}
+
+func (params *XMSSParameters) hash() hashFunc {
+	return hashFor(params.Func, params.N)
+}
+
+func (params *XMSSMTParameters) hash() hashFunc {
+	return hashFor(params.Func, params.N)
+}
+
+// treeHeight returns the height of a single tree in the hypertree.
+func (params *XMSSMTParameters) treeHeight() uint32 {
+	return params.Height / params.Layers
+}
+
+// tree returns the parameters of a single tree in the hypertree.
+func (params *XMSSMTParameters) tree() XMSSParameters {
+	return XMSSParameters{Func: params.Func, N: params.N, Height: params.treeHeight()}
+}
+
+// SignatureSize returns the length of a signature in bytes.
+func (params *XMSSParameters) SignatureSize() int {
+	n := int(params.N)
+	return 4 + n + (wlen(n)+int(params.Height))*n
+}
+
+// SignatureSize returns the length of a signature in bytes.
+func (params *XMSSMTParameters) SignatureSize() int {
+	n := int(params.N)
+	return params.indexSize() + n + (int(params.Layers)*wlen(n)+int(params.Height))*n
+}
+
+// PublicKeySize returns the length of an encoded public key in bytes.
+func (params *XMSSParameters) PublicKeySize() int {
+	return 4 + 2*int(params.N)
+}
+
+// PublicKeySize returns the length of an encoded public key in bytes.
+func (params *XMSSMTParameters) PublicKeySize() int {
+	return 4 + 2*int(params.N)
+}
+
+// indexSize returns the length of the index in a signature, which is
+// ceil(h / 8) bytes.
+func (params *XMSSMTParameters) indexSize() int {
+	return int(params.Height+7) / 8
+}
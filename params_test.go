@@ -0,0 +1,260 @@
+// Copyright (c) 2019 Benjamin Schlosser
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParameterSets(t *testing.T) {
+	for oid, params := range xmssParameterSets {
+		if oid != params.OID {
+			t.Errorf("OID of %s is incorrect", params)
+		}
+		p, err := XMSSParametersByName(params.String())
+		if err != nil {
+			t.Error(err)
+		} else if *p != params {
+			t.Errorf("%s is not found by name", params)
+		}
+	}
+	for oid, params := range xmssmtParameterSets {
+		if oid != params.OID {
+			t.Errorf("OID of %s is incorrect", params)
+		}
+		p, err := XMSSMTParametersByName(params.String())
+		if err != nil {
+			t.Error(err)
+		} else if *p != params {
+			t.Errorf("%s is not found by name", params)
+		}
+	}
+
+	p, err := XMSSParametersByOID(0x00000002)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.String() != "XMSS-SHA2_16_256" {
+		t.Errorf("name of OID 0x00000002 is incorrect: %s", p)
+	}
+	mp, err := XMSSMTParametersByOID(0x00000008)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mp.String() != "XMSSMT-SHA2_60/12_256" {
+		t.Errorf("name of OID 0x00000008 is incorrect: %s", mp)
+	}
+	if _, err := XMSSParametersByOID(0x0000000d); err == nil {
+		t.Error("OID 0x0000000d must be unknown")
+	}
+	if _, err := XMSSMTParametersByName("XMSSMT-SHA2_20/3_256"); err == nil {
+		t.Error("XMSSMT-SHA2_20/3_256 must be unknown")
+	}
+	if defaultXMSSParameters(10).OID != 0x00000001 {
+		t.Error("OID of height 10 is incorrect")
+	}
+	if defaultXMSSParameters(3).OID != 0 {
+		t.Error("height 3 must not have an OID")
+	}
+}
+
+func TestXMSSKeyPairFromParams(t *testing.T) {
+	params, err := XMSSParametersByName("XMSS-SHA2_10_256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, pub, err := NewXMSSKeyPairFromParams(params, generateSeed())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pub.OID != 0x00000001 || priv.OID != 0x00000001 {
+		t.Errorf("OID is incorrect: %d", pub.OID)
+	}
+	msg := []byte("This is a test for XMSS.")
+	sig := mustSign(t, priv, msg)
+	if len(sig) != params.SignatureSize() {
+		t.Errorf("length of signature is incorrect: %d", len(sig))
+	}
+	if !pub.Verify(sig, msg) {
+		t.Error("XMSS sig is incorrect")
+	}
+
+	legacy := pub.Export()
+	legacy.XMSSParameters = XMSSParameters{Height: 10}
+	pub2 := new(PublicKey)
+	pub2.Import(legacy)
+	if pub2.XMSSParameters != *params {
+		t.Errorf("parameters of legacy export are incorrect: %s", pub2.XMSSParameters)
+	}
+	if !pub2.Verify(sig, msg) {
+		t.Error("XMSS verification after import is incorrect")
+	}
+
+	for _, name := range []string{"XMSSMT-SHA2_20/4_256", "XMSSMT-SHAKE_20/4_256", "XMSSMT-SHA2_20/4_512"} {
+		mtParams, err := XMSSMTParametersByName(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mpriv, mpub, err := NewXMSSMTKeyPairFromParams(mtParams, generateSeed())
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig, err = mpriv.Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sig) != mtParams.SignatureSize() {
+			t.Errorf("length of signature is incorrect: %d", len(sig))
+		}
+		if !mpub.Verify(sig, msg) {
+			t.Errorf("%s sig is incorrect", name)
+		}
+	}
+}
+
+func TestUnsupportedParams(t *testing.T) {
+	for _, params := range []XMSSParameters{
+		{Func: SHA2, N: 48, Height: 10},
+		{Func: HashFunc(2), N: 32, Height: 10},
+		{Func: SHA2, N: 32, Height: 0},
+		{Func: SHA2, N: 32, Height: 32},
+		{OID: 0x00000001, Func: SHA2, N: 32, Height: 16},
+	} {
+		if _, _, err := NewXMSSKeyPairFromParams(&params, generateSeed()); err == nil {
+			t.Errorf("%s must not be supported", params)
+		}
+	}
+	mtParams := XMSSMTParameters{Func: SHAKE, N: 48, Height: 20, Layers: 2}
+	if _, _, err := NewXMSSMTKeyPairFromParams(&mtParams, generateSeed()); err == nil {
+		t.Errorf("%s must not be supported", mtParams)
+	}
+}
+
+func TestInvalidSeeds(t *testing.T) {
+	seed := generateSeed()
+	for _, bad := range [][]byte{nil, seed[:31], append(generateSeed(), 0)} {
+		if _, _, err := NewXMSSKeyPairWithParams(4, seed, bad, seed, 0, 0); err == nil {
+			t.Errorf("XMSS seed of %d bytes must not be accepted", len(bad))
+		}
+		if _, _, err := NewXMSSMTKeyPairWithParams(4, 2, bad, seed, seed); err == nil {
+			t.Errorf("XMSS^MT seed of %d bytes must not be accepted", len(bad))
+		}
+	}
+
+	priv, _, err := NewXMSSKeyPairWithParams(4, seed, seed, seed, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	export := priv.Export()
+	export.SecretKeyPRF = nil
+	if err := new(PrivateKey).Import(export); err == nil {
+		t.Error("XMSS export without SK_PRF must not be imported")
+	}
+	mpriv, _, err := NewXMSSMTKeyPairWithParams(4, 2, seed, seed, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mexport := mpriv.Export()
+	mexport.SecretKeySeed = nil
+	if err := new(PrivateKeyMT).Import(mexport); err == nil {
+		t.Error("XMSS^MT export without SK_SEED must not be imported")
+	}
+}
+
+
+//TestKeyPairFromParamsSeeds checks that the keys of all XMSS parameter sets
+//and of the XMSS^MT sets with n = 64 have seeds of n bytes, so that their
+//public keys survive MarshalBinary. Generating the trees of larger heights
+//takes too long for a test, so only keys of height 10, or of height 5 per
+//tree for XMSS^MT, are generated and verify a signature after the round
+//trip.
+func TestKeyPairFromParamsSeeds(t *testing.T) {
+	seed := generateSeed()
+	msg := []byte("This is a test for XMSS.")
+	for oid := uint32(1); oid <= uint32(len(xmssParameterSets)); oid++ {
+		params := xmssParameterSets[oid]
+		sks, skprf, pubseed := deriveSeeds(seed, params.N)
+		if len(sks) != int(params.N) || len(skprf) != int(params.N) || len(pubseed) != int(params.N) {
+			t.Errorf("length of seeds of %s is incorrect", params)
+		}
+		_, pub := newXMSSKey(params, sks, skprf, pubseed, 0)
+		var sig []byte
+		if params.Height == 10 {
+			var priv *PrivateKey
+			var err error
+			priv, pub, err = NewXMSSKeyPairFromParams(&params, seed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sig = mustSign(t, priv, msg)
+		}
+		b, err := pub.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		pub2 := new(PublicKey)
+		if err := pub2.UnmarshalBinary(b); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(pub2.publicSeed, pub.publicSeed) || !bytes.Equal(pub2.root, pub.root) {
+			t.Errorf("%s is changed by MarshalBinary", params)
+		}
+		if sig != nil && !pub2.Verify(sig, msg) {
+			t.Errorf("%s verification after MarshalBinary is incorrect", params)
+		}
+	}
+	for oid := uint32(1); oid <= uint32(len(xmssmtParameterSets)); oid++ {
+		params := xmssmtParameterSets[oid]
+		if params.N != 64 {
+			continue
+		}
+		sks, skprf, pubseed := deriveSeeds(seed, params.N)
+		pub := &newXMSSMTKey(params, sks, skprf, pubseed).PublicKeyMT
+		pub.root = make([]byte, params.N)
+		var sig []byte
+		if params.treeHeight() == 5 {
+			var priv *PrivateKeyMT
+			var err error
+			priv, pub, err = NewXMSSMTKeyPairFromParams(&params, seed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sig, err = priv.Sign(msg); err != nil {
+				t.Fatal(err)
+			}
+		}
+		b, err := pub.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		pub2 := new(PublicKeyMT)
+		if err := pub2.UnmarshalBinary(b); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(pub2.publicSeed, pub.publicSeed) || !bytes.Equal(pub2.root, pub.root) {
+			t.Errorf("%s is changed by MarshalBinary", params)
+		}
+		if sig != nil && !pub2.Verify(sig, msg) {
+			t.Errorf("%s verification after MarshalBinary is incorrect", params)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright (c) 2019 Benjamin Schlosser
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// StateStore durably records the index of the next unused one-time key of a
+// private key, so that no index is used twice after a crash.
+type StateStore interface {
+	// Load returns the recorded index. If no index has been recorded yet,
+	// it returns an error for which os.IsNotExist is true.
+	Load() (uint64, error)
+	// Store records index. It must not return before the record survives
+	// a crash.
+	Store(index uint64) error
+}
+
+// SetStateStore makes Sign record the index following each signature in
+// store before the signature is returned. If store holds a higher index
+// than priv, priv skips forward to it; the one-time keys in between are
+// never used. An empty store is initialized with the index of priv.
+func (priv *PrivateKey) SetStateStore(store StateStore) error {
+	index, err := store.Load()
+	switch {
+	case os.IsNotExist(err):
+		index = uint64(priv.m.leaf)
+		if err := store.Store(index); err != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	case index > priv.limit():
+		return fmt.Errorf("xmss: stored index %d is out of range", index)
+	}
+	priv.skipTo(uint32(index))
+	priv.store = store
+	priv.reserved = uint64(priv.m.leaf)
+	return nil
+}
+
+// Reserve records the next n indices as used in the state store with a
+// single write, so that the following n signatures are made without
+// writing to the store. Indices of the block which are not used before a
+// restart are skipped and never reused.
+func (priv *PrivateKey) Reserve(n uint64) error {
+	if priv.store == nil {
+		return errors.New("xmss: no state store to reserve indices in")
+	}
+	next := uint64(priv.m.leaf) + n
+	if next > priv.limit() {
+		next = priv.limit()
+	}
+	if next <= priv.reserved {
+		return nil
+	}
+	if err := priv.store.Store(next); err != nil {
+		return err
+	}
+	priv.reserved = next
+	return nil
+}
+
+//reserve makes sure that the index of the next signature is reserved in
+//the state store, if any.
+func (priv *PrivateKey) reserve() error {
+	if priv.store == nil || uint64(priv.m.leaf) < priv.reserved {
+		return nil
+	}
+	return priv.Reserve(1)
+}
+
+var errStateCorrupted = errors.New("xmss: state file is corrupted")
+
+// FileStateStore is a StateStore which keeps the index in a file. Store
+// writes a temporary file in the same directory, syncs it and renames it
+// over the old file, so the file always holds either the old or the new
+// index.
+type FileStateStore struct {
+	path string
+}
+
+// NewFileStateStore returns a FileStateStore which keeps the index in the
+// file at path.
+func NewFileStateStore(path string) *FileStateStore {
+	return &FileStateStore{path: path}
+}
+
+// Load reads the index from the file.
+func (s *FileStateStore) Load() (uint64, error) {
+	b, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		return 0, err
+	}
+	if len(b) != 12 || crc32.ChecksumIEEE(b[:8]) != binary.BigEndian.Uint32(b[8:]) {
+		return 0, errStateCorrupted
+	}
+	return binary.BigEndian.Uint64(b), nil
+}
+
+// Store writes index || CRC-32 of index to the file.
+func (s *FileStateStore) Store(index uint64) error {
+	b := make([]byte, 12)
+	binary.BigEndian.PutUint64(b, index)
+	binary.BigEndian.PutUint32(b[8:], crc32.ChecksumIEEE(b[:8]))
+
+	dir := filepath.Dir(s.path)
+	f, err := ioutil.TempFile(dir, filepath.Base(s.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), s.path); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return syncDir(dir)
+}
+
+//syncDir syncs the directory dir so that a rename in it is durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	err = d.Sync()
+	if cerr := d.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
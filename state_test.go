@@ -0,0 +1,240 @@
+// Copyright (c) 2019 Benjamin Schlosser
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileStateStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xmss")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "state")
+	s := NewFileStateStore(path)
+	if _, err := s.Load(); !os.IsNotExist(err) {
+		t.Errorf("empty store must return a not exist error: %v", err)
+	}
+	for _, idx := range []uint64{0, 1, 1 << 20, 1<<60 + 3} {
+		if err := s.Store(idx); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path+".tmp123", []byte("torn"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		i, err := s.Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i != idx {
+			t.Errorf("loaded index %d is not stored index %d", i, idx)
+		}
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b[7] ^= 1
+	if err := ioutil.WriteFile(path, b, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Load(); err == nil {
+		t.Error("corrupted state must not be loaded")
+	}
+	if err := ioutil.WriteFile(path, b[:4], 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Load(); err == nil {
+		t.Error("truncated state must not be loaded")
+	}
+}
+
+var errCrash = errors.New("crash")
+
+//crashStore is a StateStore which crashes at the given call of Store,
+//either before or after the index is written.
+type crashStore struct {
+	StateStore
+	crashAt    int
+	afterWrite bool
+}
+
+func (s *crashStore) Store(index uint64) error {
+	if s.crashAt--; s.crashAt != 0 {
+		return s.StateStore.Store(index)
+	}
+	if s.afterWrite {
+		if err := s.StateStore.Store(index); err != nil {
+			return err
+		}
+	}
+	return errCrash
+}
+
+func TestStateStoreCrash(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xmss")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	seed, err := hex.DecodeString("b041bf7ca73cc7905aadc1b6460da2e50206652e3d57a61487beb09664da308d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rnd := rand.New(rand.NewSource(1))
+	msg := []byte("test message")
+	used := make(map[uint32]bool)
+	fs := NewFileStateStore(filepath.Join(dir, "state"))
+	for restarts := 0; ; restarts++ {
+		if restarts > 100 {
+			t.Fatal("key is not exhausted after 100 restarts")
+		}
+		priv, pub := NewXMSSKeyPair(4, seed)
+		store := &crashStore{
+			StateStore: fs,
+			crashAt:    1 + rnd.Intn(6),
+			afterWrite: rnd.Intn(2) == 0,
+		}
+		if err := priv.SetStateStore(store); err != nil {
+			if err != errCrash {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if n := uint64(rnd.Intn(4)); n > 1 {
+			if err := priv.Reserve(n); err == errCrash {
+				continue
+			} else if err != nil {
+				t.Fatal(err)
+			}
+		}
+		//the process also crashes after a signature is released.
+		for n := rnd.Intn(6); n > 0; n-- {
+			sig, err := priv.Sign(nil, msg, nil)
+			if err == errCrash {
+				break
+			}
+			if err == ErrKeyExhausted {
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			idx := binary.BigEndian.Uint32(sig)
+			if used[idx] {
+				t.Fatalf("index %d is used twice", idx)
+			}
+			used[idx] = true
+			if !pub.Verify(sig, msg) {
+				t.Errorf("XMSS verification is incorrect (%d)", idx)
+			}
+		}
+	}
+}
+
+//countingStore is a StateStore which counts the calls of Store.
+type countingStore struct {
+	StateStore
+	n int
+}
+
+func (s *countingStore) Store(index uint64) error {
+	s.n++
+	return s.StateStore.Store(index)
+}
+
+func TestReserve(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xmss")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	seed, err := hex.DecodeString("b041bf7ca73cc7905aadc1b6460da2e50206652e3d57a61487beb09664da308d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("test message")
+	priv, pub := NewXMSSKeyPair(4, seed)
+	if err := priv.Reserve(5); err == nil {
+		t.Error("indices must not be reserved without a state store")
+	}
+	store := &countingStore{StateStore: NewFileStateStore(filepath.Join(dir, "state"))}
+	if err := priv.SetStateStore(store); err != nil {
+		t.Fatal(err)
+	}
+	if err := priv.Reserve(5); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		mustSign(t, priv, msg)
+	}
+	if store.n != 2 {
+		t.Errorf("store is written %d times", store.n)
+	}
+
+	//restart after 3 of 5 reserved indices are used.
+	priv, _ = NewXMSSKeyPair(4, seed)
+	if err := priv.SetStateStore(store); err != nil {
+		t.Fatal(err)
+	}
+	if priv.Index() != 5 {
+		t.Errorf("unused reserved indices must be skipped: %d", priv.Index())
+	}
+	if err := priv.Reserve(100); err != nil {
+		t.Fatal(err)
+	}
+	for i := 5; i < 16; i++ {
+		sig := mustSign(t, priv, msg)
+		if idx := binary.BigEndian.Uint32(sig); idx != uint32(i) {
+			t.Errorf("index of signature %d is incorrect: %d", i, idx)
+		}
+		if !pub.Verify(sig, msg) {
+			t.Errorf("XMSS verification is incorrect (%d)", i)
+		}
+	}
+	if store.n != 3 {
+		t.Errorf("store is written %d times", store.n)
+	}
+	if idx, err := store.Load(); err != nil || idx != 16 {
+		t.Errorf("reserved index %d is incorrect: %v", idx, err)
+	}
+
+	//restart after all indices are used.
+	priv, _ = NewXMSSKeyPair(4, seed)
+	if err := priv.SetStateStore(store); err != nil {
+		t.Fatal(err)
+	}
+	if priv.Index() != 16 || priv.Remaining() != 0 {
+		t.Errorf("index %d and remaining %d of a used up key are incorrect", priv.Index(), priv.Remaining())
+	}
+	if _, err := priv.Sign(nil, msg, nil); err != ErrKeyExhausted {
+		t.Errorf("used up key must not sign: %v", err)
+	}
+}
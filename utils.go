@@ -21,16 +21,41 @@
 package xmss
 
 import (
+	"bytes"
 	"crypto/x509/pkix"
 	"encoding/asn1"
+	"encoding/pem"
 	"errors"
 	"fmt"
 )
 
 var (
-	OIDBCXMSS = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 22554, 2, 2}
+	OIDBCXMSS   = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 22554, 2, 2}
+	OIDBCXMSSMT = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 22554, 2, 3}
 )
 
+// OIDs of the tree digests used by BouncyCastle.
+var (
+	oidSHA256   = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSHA512   = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}
+	oidSHAKE128 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 11}
+	oidSHAKE256 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 12}
+)
+
+// ErrTrailingData is returned if a key or a PEM block is followed by
+// other data.
+var ErrTrailingData = errors.New("x509: trailing data")
+
+// UnsupportedAlgorithmError is returned for a key whose algorithm is
+// neither XMSS nor XMSS^MT.
+type UnsupportedAlgorithmError struct {
+	Algorithm asn1.ObjectIdentifier
+}
+
+func (e UnsupportedAlgorithmError) Error() string {
+	return fmt.Sprintf("x509: unsupported public key algorithm %s", e.Algorithm)
+}
+
 // pkcs8 reflects an ASN.1, PKCS#8 PrivateKey. See RFC 5208.
 type pkcs8 struct {
 	Version    int
@@ -38,27 +63,181 @@ type pkcs8 struct {
 	PrivateKey []byte
 }
 
+// xmssKeyParams reflects the parameters of the XMSS algorithm identifier
+// of BouncyCastle.
+type xmssKeyParams struct {
+	Version    int
+	Height     int
+	TreeDigest pkix.AlgorithmIdentifier
+}
+
+// xmssMTKeyParams reflects the parameters of the XMSS^MT algorithm
+// identifier of BouncyCastle.
+type xmssMTKeyParams struct {
+	Version    int
+	Height     int
+	Layers     int
+	TreeDigest pkix.AlgorithmIdentifier
+}
+
+//treeDigest returns the tree digest for hash function family f with
+//output length n.
+func treeDigest(f HashFunc, n uint32) (pkix.AlgorithmIdentifier, error) {
+	var oid asn1.ObjectIdentifier
+	switch {
+	case f == SHA2 && n == 32:
+		oid = oidSHA256
+	case f == SHA2 && n == 64:
+		oid = oidSHA512
+	case f == SHAKE && n == 32:
+		oid = oidSHAKE128
+	case f == SHAKE && n == 64:
+		oid = oidSHAKE256
+	default:
+		return pkix.AlgorithmIdentifier{}, fmt.Errorf("x509: no tree digest for %s with n = %d", f, n)
+	}
+	return pkix.AlgorithmIdentifier{Algorithm: oid}, nil
+}
+
+//hashOfTreeDigest is the inverse of treeDigest.
+func hashOfTreeDigest(digest pkix.AlgorithmIdentifier) (HashFunc, uint32, error) {
+	switch {
+	case digest.Algorithm.Equal(oidSHA256):
+		return SHA2, 32, nil
+	case digest.Algorithm.Equal(oidSHA512):
+		return SHA2, 64, nil
+	case digest.Algorithm.Equal(oidSHAKE128):
+		return SHAKE, 32, nil
+	case digest.Algorithm.Equal(oidSHAKE256):
+		return SHAKE, 64, nil
+	}
+	return 0, 0, fmt.Errorf("x509: unknown XMSS tree digest %s", digest.Algorithm)
+}
+
+func parseXMSSKeyParams(algo pkix.AlgorithmIdentifier) (*XMSSParameters, error) {
+	var kp xmssKeyParams
+	if rest, err := asn1.Unmarshal(algo.Parameters.FullBytes, &kp); err != nil {
+		return nil, err
+	} else if len(rest) != 0 {
+		return nil, asn1.SyntaxError{Msg: "trailing data"}
+	}
+	f, n, err := hashOfTreeDigest(kp.TreeDigest)
+	if err != nil {
+		return nil, err
+	}
+	if kp.Height <= 0 || kp.Height > 32 {
+		return nil, fmt.Errorf("x509: invalid XMSS height %d", kp.Height)
+	}
+	params := newXMSSParameters(f, n, uint32(kp.Height))
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+	return &params, nil
+}
+
+func parseXMSSMTKeyParams(algo pkix.AlgorithmIdentifier) (*XMSSMTParameters, error) {
+	var kp xmssMTKeyParams
+	if rest, err := asn1.Unmarshal(algo.Parameters.FullBytes, &kp); err != nil {
+		return nil, err
+	} else if len(rest) != 0 {
+		return nil, asn1.SyntaxError{Msg: "trailing data"}
+	}
+	f, n, err := hashOfTreeDigest(kp.TreeDigest)
+	if err != nil {
+		return nil, err
+	}
+	if kp.Height <= 0 || kp.Height > 64 || kp.Layers <= 0 || kp.Layers > 64 {
+		return nil, fmt.Errorf("x509: invalid XMSS^MT height %d/%d", kp.Height, kp.Layers)
+	}
+	params := newXMSSMTParameters(f, n, uint32(kp.Height), uint32(kp.Layers))
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+	return &params, nil
+}
+
+func marshalXMSSKeyParams(params *XMSSParameters) (pkix.AlgorithmIdentifier, error) {
+	digest, err := treeDigest(params.Func, params.N)
+	if err != nil {
+		return pkix.AlgorithmIdentifier{}, err
+	}
+	b, err := asn1.Marshal(xmssKeyParams{
+		Version:    0,
+		Height:     int(params.Height),
+		TreeDigest: digest,
+	})
+	if err != nil {
+		return pkix.AlgorithmIdentifier{}, err
+	}
+	return pkix.AlgorithmIdentifier{
+		Algorithm:  OIDBCXMSS,
+		Parameters: asn1.RawValue{FullBytes: b},
+	}, nil
+}
+
+func marshalXMSSMTKeyParams(params *XMSSMTParameters) (pkix.AlgorithmIdentifier, error) {
+	digest, err := treeDigest(params.Func, params.N)
+	if err != nil {
+		return pkix.AlgorithmIdentifier{}, err
+	}
+	b, err := asn1.Marshal(xmssMTKeyParams{
+		Version:    0,
+		Height:     int(params.Height),
+		Layers:     int(params.Layers),
+		TreeDigest: digest,
+	})
+	if err != nil {
+		return pkix.AlgorithmIdentifier{}, err
+	}
+	return pkix.AlgorithmIdentifier{
+		Algorithm:  OIDBCXMSSMT,
+		Parameters: asn1.RawValue{FullBytes: b},
+	}, nil
+}
+
+// ParsePKCS8PrivateKey parses an XMSS or XMSS^MT private key in the
+// PKCS#8 format of BouncyCastle. It returns a *PrivateKey or a *PrivateKeyMT.
+// The traversal state written by MarshalPKCS8PrivateKey is restored; the one
+// of BouncyCastle is ignored and rebuilt at the index.
 func ParsePKCS8PrivateKey(der []byte) (key interface{}, err error) {
 	var privKey pkcs8
-	if _, err := asn1.Unmarshal(der, &privKey); err != nil {
+	if rest, err := asn1.Unmarshal(der, &privKey); err != nil {
 		return nil, fmt.Errorf("XMSS PKCS#8 parser: failed to unmarshal private key: %s", err)
+	} else if len(rest) != 0 {
+		return nil, ErrTrailingData
 	}
 
-	if privKey.Algo.Algorithm.Equal(OIDBCXMSS) {
-		key, err = parseXMSSPrivateKey(privKey.PrivateKey)
+	switch {
+	case privKey.Algo.Algorithm.Equal(OIDBCXMSS):
+		params, err := parseXMSSKeyParams(privKey.Algo)
 		if err != nil {
 			return nil, fmt.Errorf("x509: PKCS#8 parsing of xmss private key failed: %s", err)
 		}
+		key, err = parseXMSSPrivateKey(privKey.PrivateKey, params)
+		if err != nil {
+			return nil, fmt.Errorf("x509: PKCS#8 parsing of xmss private key failed: %s", err)
+		}
+		return key, err
+	case privKey.Algo.Algorithm.Equal(OIDBCXMSSMT):
+		params, err := parseXMSSMTKeyParams(privKey.Algo)
+		if err != nil {
+			return nil, fmt.Errorf("x509: PKCS#8 parsing of xmss^mt private key failed: %s", err)
+		}
+		key, err = parseXMSSMTPrivateKey(privKey.PrivateKey, params)
+		if err != nil {
+			return nil, fmt.Errorf("x509: PKCS#8 parsing of xmss^mt private key failed: %s", err)
+		}
 		return key, err
 	}
 
-	return nil, fmt.Errorf("x509: PKCS#8 parsing of xmss private key failed")
+	return nil, UnsupportedAlgorithmError{privKey.Algo.Algorithm}
 }
 
 type pkcs8XMSSPrivateKey struct {
 	Version  int
 	Data     pkcs8XMSSPrivateKeyData
-	BdsState []byte `asn1:"explicit"`
+	BdsState []byte `asn1:"optional,explicit"`       // serialized Java object of BouncyCastle
+	State    []byte `asn1:"optional,explicit,tag:1"` // traversal state of this package
 }
 
 type pkcs8XMSSPrivateKeyData struct {
@@ -67,9 +246,39 @@ type pkcs8XMSSPrivateKeyData struct {
 	SecretKeyPRF  []byte
 	PublicSeed    []byte
 	Root          []byte
+	MaxIndex      asn1.RawValue `asn1:"optional"` // [0] IMPLICIT INTEGER in version 1
 }
 
-func parseXMSSPrivateKey(der []byte) (*PrivateKey, error) {
+//end returns the index after the last usable key, which is
+//MaxIndex+1, or 0 if MaxIndex is absent.
+func (data *pkcs8XMSSPrivateKeyData) end() (uint64, error) {
+	if len(data.MaxIndex.FullBytes) == 0 {
+		return 0, nil
+	}
+	var maxIndex int64
+	if _, err := asn1.UnmarshalWithParams(data.MaxIndex.FullBytes, &maxIndex, "tag:0"); err != nil {
+		return 0, err
+	}
+	if maxIndex < int64(data.Index)-1 {
+		return 0, errors.New("x509: maximum index of XMSS private key is out of range")
+	}
+	return uint64(maxIndex) + 1, nil
+}
+
+//setEnd sets MaxIndex to end-1 if end is not 0.
+func (data *pkcs8XMSSPrivateKeyData) setEnd(end uint64) error {
+	if end == 0 {
+		return nil
+	}
+	b, err := asn1.MarshalWithParams(int64(end-1), "tag:0")
+	if err != nil {
+		return err
+	}
+	data.MaxIndex = asn1.RawValue{FullBytes: b}
+	return nil
+}
+
+func parseXMSSPrivateKey(der []byte, params *XMSSParameters) (*PrivateKey, error) {
 	var privKey pkcs8XMSSPrivateKey
 	rest, err := asn1.Unmarshal(der, &privKey)
 	if len(rest) > 0 {
@@ -78,36 +287,104 @@ func parseXMSSPrivateKey(der []byte) (*PrivateKey, error) {
 	if err != nil {
 		return nil, err
 	}
+	if privKey.Data.Index < 0 || uint64(privKey.Data.Index) > 1<<params.Height {
+		return nil, errors.New("x509: index of XMSS private key is out of range")
+	}
+	end, err := privKey.Data.end()
+	if err != nil {
+		return nil, err
+	}
+	if end > 1<<params.Height {
+		return nil, errors.New("x509: maximum index of XMSS private key is out of range")
+	}
 
 	privKeyExport := &PrivateKeyExport{
 		PublicKeyExport: PublicKeyExport{
-			XMSSParameters: XMSSParameters{Height: 10},
+			XMSSParameters: *params,
 			PublicSeed:     privKey.Data.PublicSeed,
 			Root:           privKey.Data.Root,
 		},
 		Index:         uint32(privKey.Data.Index),
 		SecretKeySeed: privKey.Data.SecretKeySeed,
 		SecretKeyPRF:  privKey.Data.SecretKeyPRF,
+		BdsState:      privKey.State,
+		End:           end,
+	}
+	if privKey.State == nil {
+		//earlier versions wrote the state in the field of BouncyCastle.
+		privKeyExport.BdsState = privKey.BdsState
 	}
 
 	key := new(PrivateKey)
-	key.Import(privKeyExport)
+	if err := key.Import(privKeyExport); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func parseXMSSMTPrivateKey(der []byte, params *XMSSMTParameters) (*PrivateKeyMT, error) {
+	var privKey pkcs8XMSSPrivateKey
+	rest, err := asn1.Unmarshal(der, &privKey)
+	if len(rest) > 0 {
+		return nil, asn1.SyntaxError{Msg: "trailing data"}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if privKey.Data.Index < 0 || (params.Height < 63 && uint64(privKey.Data.Index) > 1<<params.Height) {
+		return nil, errors.New("x509: index of XMSS^MT private key is out of range")
+	}
+
+	key := new(PrivateKeyMT)
+	err = key.Import(&PrivateKeyMTExport{
+		PublicKeyMTExport: PublicKeyMTExport{
+			XMSSMTParameters: *params,
+			PublicSeed:       privKey.Data.PublicSeed,
+			Root:             privKey.Data.Root,
+		},
+		Index:         uint64(privKey.Data.Index),
+		SecretKeySeed: privKey.Data.SecretKeySeed,
+		SecretKeyPRF:  privKey.Data.SecretKeyPRF,
+	})
+	if err != nil {
+		return nil, err
+	}
 
 	return key, nil
 }
 
+// MarshalPKCS8PrivateKey encodes key in the PKCS#8 format of BouncyCastle.
+// The traversal state of the key is appended as an optional [1] field, so
+// that ParsePKCS8PrivateKey does not rebuild it, which costs O(2^h) hashing.
+// BouncyCastle does not know the field; use MarshalPKCS8PrivateKeyBC for keys
+// to be read by BouncyCastle.
 func MarshalPKCS8PrivateKey(key *PrivateKey) ([]byte, error) {
+	return marshalPKCS8PrivateKey(key, true)
+}
+
+// MarshalPKCS8PrivateKeyBC is MarshalPKCS8PrivateKey without the traversal
+// state. BouncyCastle stores its state as a serialized Java object, so
+// BouncyCastle and ParsePKCS8PrivateKey rebuild it at the index.
+func MarshalPKCS8PrivateKeyBC(key *PrivateKey) ([]byte, error) {
+	return marshalPKCS8PrivateKey(key, false)
+}
+
+func marshalPKCS8PrivateKey(key *PrivateKey, withState bool) ([]byte, error) {
 	if key == nil {
 		return nil, errors.New("invalid xmss private key - it must be different from nil")
 	}
-	asn1Bytes, err := marshalXMSSPrivateKey(key)
+	asn1Bytes, err := marshalXMSSPrivateKey(key, withState)
 	if err != nil {
 		return nil, fmt.Errorf("error marshalling XMSS private key to asn1 [%s]", err)
 	}
 
 	var pkcs8Key pkcs8
 	pkcs8Key.Version = 0
-	pkcs8Key.Algo.Algorithm = OIDBCXMSS
+	pkcs8Key.Algo, err = marshalXMSSKeyParams(&key.XMSSParameters)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling XMSS private key to asn1 [%s]", err)
+	}
 	pkcs8Key.PrivateKey = asn1Bytes
 
 	pkcs8Bytes, err := asn1.Marshal(pkcs8Key)
@@ -117,7 +394,7 @@ func MarshalPKCS8PrivateKey(key *PrivateKey) ([]byte, error) {
 	return pkcs8Bytes, nil
 }
 
-func marshalXMSSPrivateKey(key *PrivateKey) ([]byte, error) {
+func marshalXMSSPrivateKey(key *PrivateKey, withState bool) ([]byte, error) {
 	keyExport := key.Export()
 
 	pkcs8XMSSKey := pkcs8XMSSPrivateKey{
@@ -129,33 +406,141 @@ func marshalXMSSPrivateKey(key *PrivateKey) ([]byte, error) {
 			PublicSeed: keyExport.PublicSeed,
 			Root: keyExport.Root,
 		},
-		BdsState: nil,
+	}
+	if keyExport.End != 0 {
+		pkcs8XMSSKey.Version = 1
+		if err := pkcs8XMSSKey.Data.setEnd(keyExport.End); err != nil {
+			return nil, err
+		}
+	}
+	if withState {
+		pkcs8XMSSKey.State = keyExport.BdsState
 	}
 
 	return asn1.Marshal(pkcs8XMSSKey)
 }
 
+// MarshalPKCS8PrivateKeyMT is the XMSS^MT counterpart of MarshalPKCS8PrivateKey.
+// The trees of the layers are not stored and are rebuilt after parsing.
+func MarshalPKCS8PrivateKeyMT(key *PrivateKeyMT) ([]byte, error) {
+	if key == nil {
+		return nil, errors.New("invalid xmss^mt private key - it must be different from nil")
+	}
+	keyExport := key.Export()
+	asn1Bytes, err := asn1.Marshal(pkcs8XMSSPrivateKey{
+		Version: 0,
+		Data: pkcs8XMSSPrivateKeyData{
+			Index:         int(keyExport.Index),
+			SecretKeySeed: keyExport.SecretKeySeed,
+			SecretKeyPRF:  keyExport.SecretKeyPRF,
+			PublicSeed:    keyExport.PublicSeed,
+			Root:          keyExport.Root,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling XMSS^MT private key to asn1 [%s]", err)
+	}
+
+	var pkcs8Key pkcs8
+	pkcs8Key.Version = 0
+	pkcs8Key.Algo, err = marshalXMSSMTKeyParams(&key.XMSSMTParameters)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling XMSS^MT private key to asn1 [%s]", err)
+	}
+	pkcs8Key.PrivateKey = asn1Bytes
+
+	pkcs8Bytes, err := asn1.Marshal(pkcs8Key)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling XMSS^MT private key to asn1 [%s]", err)
+	}
+	return pkcs8Bytes, nil
+}
+
 type publicKeyInfo struct {
 	Raw       asn1.RawContent
 	Algorithm pkix.AlgorithmIdentifier
 	PublicKey asn1.BitString
 }
 
+// ParsePKIXPublicKey parses an XMSS or XMSS^MT public key in the PKIX format
+// of BouncyCastle. It returns a *PublicKey or a *PublicKeyMT.
 func ParsePKIXPublicKey(der []byte) (pub interface{}, err error) {
 	var pki publicKeyInfo
 	if rest, err := asn1.Unmarshal(der, &pki); err != nil {
 		return nil, err
 	} else if len(rest) != 0 {
-		return nil, asn1.SyntaxError{Msg: "trailing data"}
+		return nil, ErrTrailingData
 	}
-	if pki.Algorithm.Algorithm.Equal(OIDBCXMSS) {
-		pub, err = parseXMSSPublicKey(pki.PublicKey.Bytes)
+	switch {
+	case pki.Algorithm.Algorithm.Equal(OIDBCXMSS):
+		params, err := parseXMSSKeyParams(pki.Algorithm)
+		if err != nil {
+			return nil, fmt.Errorf("x509: PKCS#8 parsing of xmss public key failed: %s", err)
+		}
+		pub, err = parseXMSSPublicKey(pki.PublicKey.Bytes, params)
 		if err != nil {
 			return nil, fmt.Errorf("x509: PKCS#8 parsing of xmss public key failed: %s", err)
 		}
 		return pub, err
+	case pki.Algorithm.Algorithm.Equal(OIDBCXMSSMT):
+		params, err := parseXMSSMTKeyParams(pki.Algorithm)
+		if err != nil {
+			return nil, fmt.Errorf("x509: PKCS#8 parsing of xmss^mt public key failed: %s", err)
+		}
+		pub, err = parseXMSSMTPublicKey(pki.PublicKey.Bytes, params)
+		if err != nil {
+			return nil, fmt.Errorf("x509: PKCS#8 parsing of xmss^mt public key failed: %s", err)
+		}
+		return pub, err
+	}
+	return nil, UnsupportedAlgorithmError{pki.Algorithm.Algorithm}
+}
+
+// MarshalPKIXPublicKey converts an XMSS public key to the PKIX format
+// of BouncyCastle, which is accepted by ParsePKIXPublicKey.
+func MarshalPKIXPublicKey(pub *PublicKey) ([]byte, error) {
+	if pub == nil {
+		return nil, errors.New("invalid xmss public key - it must be different from nil")
+	}
+	algo, err := marshalXMSSKeyParams(&pub.XMSSParameters)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling XMSS public key to asn1 [%s]", err)
+	}
+	return marshalPKIXPublicKey(algo, pub.publicSeed, pub.root)
+}
+
+// MarshalPKIXPublicKeyMT is the XMSS^MT counterpart of MarshalPKIXPublicKey.
+func MarshalPKIXPublicKeyMT(pub *PublicKeyMT) ([]byte, error) {
+	if pub == nil {
+		return nil, errors.New("invalid xmss^mt public key - it must be different from nil")
+	}
+	algo, err := marshalXMSSMTKeyParams(&pub.XMSSMTParameters)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling XMSS^MT public key to asn1 [%s]", err)
 	}
-	return nil, errors.New("x509: public key algorithm is not XMSS")
+	return marshalPKIXPublicKey(algo, pub.publicSeed, pub.root)
+}
+
+func marshalPKIXPublicKey(algo pkix.AlgorithmIdentifier, publicSeed, root []byte) ([]byte, error) {
+	pubBytes, err := asn1.Marshal(xmssPublicKeyData{
+		Version:    0,
+		PublicSeed: publicSeed,
+		Root:       root,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling XMSS public key to asn1 [%s]", err)
+	}
+	pkixBytes, err := asn1.Marshal(publicKeyInfo{
+		Algorithm: algo,
+		PublicKey: asn1.BitString{
+			Bytes:     pubBytes,
+			BitLength: 8 * len(pubBytes),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling XMSS public key to asn1 [%s]", err)
+	}
+	return pkixBytes, nil
 }
 
 type xmssPublicKeyData struct {
@@ -164,7 +549,7 @@ type xmssPublicKeyData struct {
 	Root       []byte
 }
 
-func parseXMSSPublicKey(der []byte) (*PublicKey, error) {
+func parseXMSSPublicKey(der []byte, params *XMSSParameters) (*PublicKey, error) {
 	var pubKey xmssPublicKeyData
 	rest, err := asn1.Unmarshal(der, &pubKey)
 	if err != nil {
@@ -173,9 +558,12 @@ func parseXMSSPublicKey(der []byte) (*PublicKey, error) {
 	if len(rest) != 0 {
 		return nil, errors.New("x509: trailing data after XMSS public key")
 	}
+	if err := validateSeeds(params.N, pubKey.PublicSeed, pubKey.Root); err != nil {
+		return nil, err
+	}
 
 	pubKeyExport := &PublicKeyExport{
-		XMSSParameters: XMSSParameters{Height: 10},
+		XMSSParameters: *params,
 		PublicSeed:     pubKey.PublicSeed,
 		Root:           pubKey.Root,
 	}
@@ -184,4 +572,114 @@ func parseXMSSPublicKey(der []byte) (*PublicKey, error) {
 	key.Import(pubKeyExport)
 
 	return key, nil
-}
\ No newline at end of file
+}
+
+func parseXMSSMTPublicKey(der []byte, params *XMSSMTParameters) (*PublicKeyMT, error) {
+	var pubKey xmssPublicKeyData
+	rest, err := asn1.Unmarshal(der, &pubKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 {
+		return nil, errors.New("x509: trailing data after XMSS^MT public key")
+	}
+	if err := validateSeeds(params.N, pubKey.PublicSeed, pubKey.Root); err != nil {
+		return nil, err
+	}
+
+	key := new(PublicKeyMT)
+	key.Import(&PublicKeyMTExport{
+		XMSSMTParameters: *params,
+		PublicSeed:       pubKey.PublicSeed,
+		Root:             pubKey.Root,
+	})
+
+	return key, nil
+}
+
+// PEM block types of keys and signatures.
+const (
+	pemPrivateKey = "PRIVATE KEY"
+	pemPublicKey  = "PUBLIC KEY"
+	pemSignature  = "XMSS SIGNATURE"
+)
+
+// EncodePEM encodes a *PrivateKey or *PrivateKeyMT as a "PRIVATE KEY" block
+// and a *PublicKey or *PublicKeyMT as a "PUBLIC KEY" block.
+func EncodePEM(key interface{}) ([]byte, error) {
+	var der []byte
+	var err error
+	typ := pemPublicKey
+	switch k := key.(type) {
+	case *PrivateKey:
+		typ = pemPrivateKey
+		der, err = MarshalPKCS8PrivateKey(k)
+	case *PrivateKeyMT:
+		typ = pemPrivateKey
+		der, err = MarshalPKCS8PrivateKeyMT(k)
+	case *PublicKey:
+		der, err = MarshalPKIXPublicKey(k)
+	case *PublicKeyMT:
+		der, err = MarshalPKIXPublicKeyMT(k)
+	default:
+		return nil, fmt.Errorf("x509: unsupported key type %T", key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), nil
+}
+
+// DecodePEM decodes a single "PRIVATE KEY" or "PUBLIC KEY" block. Depending
+// on the OID of the key it returns a *PrivateKey, *PrivateKeyMT, *PublicKey
+// or *PublicKeyMT. Unlike pem.Decode, it fails if data contains anything
+// but white space before or after the block, returning ErrTrailingData for
+// data after it.
+func DecodePEM(data []byte) (interface{}, error) {
+	block, err := decodePEMBlock(data)
+	if err != nil {
+		return nil, err
+	}
+	switch block.Type {
+	case pemPrivateKey:
+		return ParsePKCS8PrivateKey(block.Bytes)
+	case pemPublicKey:
+		return ParsePKIXPublicKey(block.Bytes)
+	}
+	return nil, fmt.Errorf("x509: unexpected PEM block type %q", block.Type)
+}
+
+// EncodeSignaturePEM encodes sig as an "XMSS SIGNATURE" block.
+func EncodeSignaturePEM(sig []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: pemSignature, Bytes: sig})
+}
+
+// DecodeSignaturePEM decodes a single "XMSS SIGNATURE" block. Like
+// DecodePEM, it fails if data contains anything else but white space.
+func DecodeSignaturePEM(data []byte) ([]byte, error) {
+	block, err := decodePEMBlock(data)
+	if err != nil {
+		return nil, err
+	}
+	if block.Type != pemSignature {
+		return nil, fmt.Errorf("x509: unexpected PEM block type %q", block.Type)
+	}
+	return block.Bytes, nil
+}
+
+//decodePEMBlock decodes the only PEM block in data.
+func decodePEMBlock(data []byte) (*pem.Block, error) {
+	data = bytes.TrimSpace(data)
+	block, rest := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("x509: no PEM block found")
+	}
+	//pem.Decode skips anything before the block.
+	if !bytes.HasPrefix(data, []byte("-----BEGIN ")) {
+		return nil, errors.New("x509: data before PEM block")
+	}
+	if len(bytes.TrimSpace(rest)) != 0 {
+		return nil, ErrTrailingData
+	}
+	return block, nil
+}
@@ -22,7 +22,12 @@ package xmss
 
 import (
 	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
 	"encoding/hex"
 	"encoding/pem"
 	"strings"
@@ -174,7 +179,7 @@ func TestParseXMSSKeys(t *testing.T) {
 		t.Errorf("PrivateKey is not correct -> publicSeed: %X", publicKey.publicSeed)
 	}
 
-	sig := privateKey.Sign([]byte("Test Nachricht"))
+	sig := mustSign(t, privateKey, []byte("Test Nachricht"))
 	exSig := "000000009EF61C4FCB0A796188EE7DB4035952CC8128F0B791511CA1602757968D2F1A2634FA45456AB2857CC69F9098E53A06FFAD773D5228686BCC0777313E11C128E0AD797791B447D1BF468EA53957684365C229F37E311826AECFCF34FF80A23A344FD69F38F28C475613E9627833519D31A55FD6263588042B7D5E653B084E6E3FF1F26BEC43025634DA84CC9EB9A1D1C6B53CA2B2C05F4B9DE7313897A70FF659E14A9442EF19024E68DB96703A882767AABBAD1EC05574BEF961EC166299EEBF513285E3CD84F798A6FC6C1105AAD95736C05ED29A0D1BBD63457B839197EAF563EC0DB16579207C1156C6B1EF1EDB276F198C892DA6210B796FEF0941E8F0060519D9387E6E8BA7778DD1E3F8574E42AD215BDC2D1040D489EE39C1A564130D7819BB229BB4C280AC50F5B8F88BCAB9E40F4C9F4FECE8628C777713B2DEE00D295D2196F8A706544700C45D837E11FDBFF2347FE65A22221ADF14104FA7AB6289753B7A9C74F7FEDD3C1F7C4CE5802A341E0DE31BB90C61671A835FB58A631C04EAD9B7B6370CAF4BC6CF4C055A5F8E3A375EB677356228421E9C38E824D5FF9A098D0BE62DB3431A48220AE35C791161274E2DCC8FD82480BB50DB059564EA705062939B34EFEE01E18DCD6A12A2A18AF94FD0EB54133699E9C7F42E20560010FB8C63544D59A46464EB03354932E1C6502E8B5F9A777338F80403E193E048B562B7F5C25E3FA320E7BECE30D363F0D32ED6F08402515C109EFCBEF5EDBF740E9BACDC475C0034327604EA4171E0EF477A7438380A10A5F97CF1D9756020C90394D8F3B617BF27EA465DD0A73383CAF1A6193152E68B7A4B26B62BB9F24329F493EBD4A618083B9E5D276D6B3DF949E814EE5611D808FF4D26ECFD1101B8EDE367D8EC245EC3CFEABE4C384FD44349509226BA2F74149E6CD33DEA730A1EAF116E7C346321452250246CE56F15569307FB55C9827562A6776AAA8CC27EAB62D61A643F401C9FF773ABBC27B3E0946DC140C9B211A9E5ED30B1D0F1DF748A09B1BF0E4FE60005E452C48A7BB55C22FA1CAD8D1D8D986B449E5BAE6127ADAA66AFE60D4B224794B0F6DCDC96AFACC84C3BC07D8EF2447E676B7028DA2815A6C946B3D71F327E0CB90E7B78DFE4D67D1BFB9015AD56273E446956A2D97A0EEE2439FAFE67FB9C476DF1CABDBE93701063B62DA974556BEFF076E693F4ACDFF869FFCB0892CC69B47683827186E77929ABCCA46E335ECC3ADC21159D4FAE28FD8AF6B254FD4BEF1AF08BD66C3037ED6F01ADDC6022424B31B96D93B96D1E3F1AF53A4CE3FC52CF2046B83B1943C27BEA642C50F115C788F9D7225044DAAFFC4FB19C91736F2F0F3704796A9FB38B58614BF685C2E2E22040FCEE8A9D01C58938E55959A08CC0FCA261F624192E37CA807952B054007C5E5F499A4519C51A5B3E06EFF16B6FF8546A3B8620502FF39CE72CD0C3E922EAA1518FDDD4AD5D8BD6C5FE103055974BB42F258E007F9065BE6E57CAB3FB4D772E02241A25563076A76C01CD95317AA7DD0B64E0B1338C5FEB2930CD288EBC68F97D18FB3B6DA36A68EF80D36ED3EE3BD31FFCE4B869A7B937DA86A36E5A137BB7FADB09A696A1CC51CA42BA7FFBCC685A282464FD703809EF045A5A6706D3BAEA43CFCE31689ECB15DBA28210AC7330028EC7733152E84B8852B926A854E356824AD14F9DF82AC992EBCA458DD0662C2416C98B0F0B6DA4F261D1845B9CEEDDA8D2239227DABDBB5BBB58D921240319099A560FED67C624A4851BA80D178CD0FA99D4071260243246A6E0F819FA0195C19A338DC394D6B2A42DEE4D8791727D10E83CFC2C5633532EC08414437D0FCA995E62842CB3C8BA5A1234D98DDE2DD12DAAB5C29A031E41D4082763FD3F603351E046C60838935F6DBF28B243F7CCABF8207ABA48ECCE1384183001F574B69323FB230B58C4D5DE8365EAF5B82180078A6DCE803C7EA7AB78C1E10AD2ECD3D2B2A95B4E69A05107C0467C39EB90013C7DA270EC4D04A8F9FA1F2A3357E7B38ACD5CF38ACC10DDABFB27E8BE697A3A088C82772BD836834486FF82CA458B9CAE89A2ECE7671E140710D407A0F5B66F72785C56F173AF4ABB66E653A8942620080B4BC65A0523C0B04EC4688F842E82B567951AEFB24853FCB9C4678852A9133108A11B6FB4C555042BA0E7153E612EC054CD8DC852C061E80437E641200BF1EB933E6EE881837E0F4E9DD6C731AAABA07D860607960892436175998796415C02D89C3AD521E4C7CBCC34013ED2DE8C828B6D796AF38BB77FC9B713546315B15A5871D389F9BBDD857881AFB0EA994FF37828685F3769B6D2CC080EFED652B653B6C7CD01B166A3475D44D8334B6040DFCF60087980E0B13E747821BE4EDFC88A49D1602E109E145E398F68C92070FFEA7538E3DC8AC1582B2A34129F0BE9A842DF6BB1B473F4AE17E1DA6240DEF1D3E262ED9798F2C3DC53BBBE6ABA251BEE281B9054B12949FDED41B7A746C2C7EFD807E0E88F4ED97343E7F6A26BCD32A6DD65E92C0979D397773384B373FBC266F399C1E3B114A91A3272D0DA48FF0188A4BAC079801ECBBC97E9D7F768E1E177C8D9019AF76F1979BFF77E62897EC933638FD192CAC46FAF93432FB844B207DE084A9E410BCD0DEAF1713F53942E994F9940B75F7C7FD3B90A7187E861ADFBA6368A9E2529F7637456D2FED115F411F6DF24FC5B5354FA240762B974168897D8B0B1E7C69A8C6812D48ED592EA1B9301CA50F0B493F2ED185789E04EA3F8E9CC3D230688645CCE05196631501CCBBAF6BD50319216949B1D2886FFA93EFB68B49F007E88C5F57738F24361D51BFBAF8DE9672B95494C66BE85E72A9F63C6C58E2B0A4227665CD1647414A07E2C56783D08CDB90E1C86E178DC6422A36F7FC8179108B2B98A4714F66AD340B07D0028CFCDD3B4F9BA803E5C09C9FAA939B7F493C2C72430F835A4CC0EB22A0E65FF903E148778D8DF50EB45563E9C6B0964D10ACB92BED21EF1C19CD236EF0529522BBBC18AED52CBD8FE5848D3CFAE6CEACB2F60AA1AAF108BE067AD94EF347D7A0247A2EB9B3C3E3BC86E54E34E2E7C5DFEBAB0A5736773D182D2B1809F108B6F8EBCCF4FC01BE8A1D8B0E7B341443F2784746F87D108958269F63B23299D9AD3A35D4339DE6C887CEBD983866748FA25F360B185551B9716A7AAD6130C86750D60E37456ABE2D49DFEC8A9030869E3AC7C58CE7C6D6246B4B97157A66326DDF31268BB3761506F0F368ABA39729E021D30798DE33B2C3C7226D176A20AC20A01589CAE7A35E438D904C6B9BD809DB169674AF7C927B0D89DFB265AEE02E5027C6D1DD863A8D5F2230A3AF0F06F29598E7850B059CD538E56BB532D81896F06A535C4CD106E5715B8A9140FD29FD6E86D2F02D4C75369C3DADA60FDA7E1942DA5A38A5C1E884DB13AE857D3D69DDE8EE27D74F10D899045212B2EE0309F0541E990F946F42564ABE8FDEA73571F4E579F751377AB5BA11316CF9B9869FE8D"
 	if strings.ToUpper(hex.EncodeToString(sig)) != exSig {
 		t.Errorf("Signature is not correct: %T", sig)
@@ -244,4 +249,427 @@ func TestMarshalPKCS8PrivateKey(t *testing.T) {
 	if !bytes.Equal(privKeyExport.SecretKeySeed, privKey2Export.SecretKeySeed) {
 		t.Errorf("SecretKeySeed is different: %v, %v", privKeyExport.SecretKeySeed, privKey2Export.SecretKeySeed)
 	}
-}
\ No newline at end of file
+}
+
+func TestMarshalPKCS8PrivateKeyBdsState(t *testing.T) {
+	seed, err := hex.DecodeString("5F706A93A124CB56BE67FF5F1133FD7EB62A36CB182AEF97B9559746DF3F1936")
+	if err != nil {
+		t.Fatal(err)
+	}
+	privKey, _ := NewXMSSKeyPair(10, seed)
+	msg := []byte("Test Nachricht")
+	for i := 0; i < 37; i++ {
+		mustSign(t, privKey, msg)
+	}
+	derData, err := MarshalPKCS8PrivateKey(privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pk pkcs8
+	if _, err := asn1.Unmarshal(derData, &pk); err != nil {
+		t.Fatal(err)
+	}
+	var xk pkcs8XMSSPrivateKey
+	if _, err := asn1.Unmarshal(pk.PrivateKey, &xk); err != nil {
+		t.Fatal(err)
+	}
+	if xk.BdsState != nil || xk.State == nil {
+		t.Error("traversal state must be in the field of this package")
+	}
+	key, err := ParsePKCS8PrivateKey(derData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privKey2 := key.(*PrivateKey)
+	if privKey2.m.leaf != 37 {
+		t.Errorf("Index is different: %d", privKey2.m.leaf)
+	}
+	if !bytes.Equal(privKey2.m.marshalBDS(privKey2.N), xk.State) {
+		t.Error("traversal state is not restored")
+	}
+
+	bcData, err := MarshalPKCS8PrivateKeyBC(privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := asn1.Unmarshal(bcData, &pk); err != nil {
+		t.Fatal(err)
+	}
+	xk = pkcs8XMSSPrivateKey{}
+	if _, err := asn1.Unmarshal(pk.PrivateKey, &xk); err != nil {
+		t.Fatal(err)
+	}
+	if xk.BdsState != nil || xk.State != nil {
+		t.Error("traversal state must be left out for BouncyCastle")
+	}
+	key, err = ParsePKCS8PrivateKey(bcData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privKey4 := key.(*PrivateKey)
+
+	export := privKey.Export()
+	privKey3 := new(PrivateKey)
+	if err := privKey3.Import(export); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 30; i++ {
+		sig := mustSign(t, privKey, msg)
+		if !bytes.Equal(sig, mustSign(t, privKey2, msg)) {
+			t.Fatalf("signature %d after parsing is different", 37+i)
+		}
+		if !bytes.Equal(sig, mustSign(t, privKey3, msg)) {
+			t.Fatalf("signature %d after import is different", 37+i)
+		}
+		if !bytes.Equal(sig, mustSign(t, privKey4, msg)) {
+			t.Fatalf("signature %d after rebuilding is different", 37+i)
+		}
+	}
+
+	for _, i := range []int{4, 40, len(export.BdsState) - 1} {
+		export.BdsState[i] ^= 1
+		if _, err := privKey3.unmarshalBDS(export.BdsState); err == nil {
+			t.Errorf("corrupted traversal state at byte %d must not be decoded", i)
+		}
+		export.BdsState[i] ^= 1
+	}
+	export.Index++
+	if err := new(PrivateKey).Import(export); err == nil {
+		t.Error("traversal state for another index must not be accepted")
+	}
+}
+
+//TestParseKeyFieldLengths checks that keys whose seeds or root are not n
+//bytes long are rejected.
+func TestParseKeyFieldLengths(t *testing.T) {
+	seed := generateSeed()
+	priv, pub := NewXMSSKeyPair(4, seed)
+	mpriv, mpub, err := NewXMSSMTKeyPair(4, 2, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	xmssAlgo, err := marshalXMSSKeyParams(&priv.XMSSParameters)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mtAlgo, err := marshalXMSSMTKeyParams(&mpriv.XMSSMTParameters)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range []struct {
+		algo             pkix.AlgorithmIdentifier
+		der              []byte
+		publicSeed, root []byte
+	}{
+		{xmssAlgo, mustMarshal(MarshalPKCS8PrivateKey(priv)), pub.publicSeed, pub.root},
+		{mtAlgo, mustMarshal(MarshalPKCS8PrivateKeyMT(mpriv)), mpub.publicSeed, mpub.root},
+	} {
+		var pk pkcs8
+		if _, err := asn1.Unmarshal(k.der, &pk); err != nil {
+			t.Fatal(err)
+		}
+		var xk pkcs8XMSSPrivateKey
+		if _, err := asn1.Unmarshal(pk.PrivateKey, &xk); err != nil {
+			t.Fatal(err)
+		}
+		for _, field := range []*[]byte{&xk.Data.SecretKeySeed, &xk.Data.SecretKeyPRF, &xk.Data.PublicSeed, &xk.Data.Root} {
+			orig := *field
+			for _, bad := range [][]byte{orig[:31], append(orig[:32:32], 0)} {
+				*field = bad
+				pk.PrivateKey = mustMarshal(asn1.Marshal(xk))
+				if _, err := ParsePKCS8PrivateKey(mustMarshal(asn1.Marshal(pk))); err == nil {
+					t.Errorf("private key with a field of %d bytes must not be parsed", len(bad))
+				}
+			}
+			*field = orig
+		}
+
+		for _, bad := range [][]byte{k.root[:31], append(k.root[:32:32], 0)} {
+			der, err := marshalPKIXPublicKey(k.algo, k.publicSeed, bad)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := ParsePKIXPublicKey(der); err == nil {
+				t.Errorf("public key with a root of %d bytes must not be parsed", len(bad))
+			}
+			if der, err = marshalPKIXPublicKey(k.algo, bad, k.root); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := ParsePKIXPublicKey(der); err == nil {
+				t.Errorf("public key with a public seed of %d bytes must not be parsed", len(bad))
+			}
+		}
+	}
+}
+
+func mustMarshal(der []byte, err error) []byte {
+	if err != nil {
+		panic(err)
+	}
+	return der
+}
+
+func TestMarshalXMSSKeyParams(t *testing.T) {
+	algo, err := marshalXMSSKeyParams(&XMSSParameters{Func: SHA2, N: 32, Height: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := asn1.Marshal(algo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemKey, _ := pem.Decode([]byte(privateKey))
+	if !bytes.Contains(pemKey.Bytes, b) {
+		t.Errorf("algorithm identifier is different from BouncyCastle: %X", b)
+	}
+}
+
+func TestMarshalPKCS8PrivateKeyHeights(t *testing.T) {
+	seed, err := hex.DecodeString("5F706A93A124CB56BE67FF5F1133FD7EB62A36CB182AEF97B9559746DF3F1936")
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("Test Nachricht")
+	for _, params := range []*XMSSParameters{
+		{Func: SHA2, N: 32, Height: 4},
+		{Func: SHA2, N: 64, Height: 5},
+		{Func: SHAKE, N: 32, Height: 6},
+		{Func: SHAKE, N: 64, Height: 10, OID: 0x0a},
+	} {
+		privKey, pubKey, err := NewXMSSKeyPairFromParams(params, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mustSign(t, privKey, msg)
+		derData, err := MarshalPKCS8PrivateKey(privKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, err := ParsePKCS8PrivateKey(derData)
+		if err != nil {
+			t.Fatal(err)
+		}
+		privKey2 := key.(*PrivateKey)
+		if privKey2.XMSSParameters != newXMSSParameters(params.Func, params.N, params.Height) {
+			t.Errorf("parameters are different: %s, %s", params, privKey2.XMSSParameters)
+		}
+		if !pubKey.Verify(mustSign(t, privKey2, msg), msg) {
+			t.Errorf("verification of %s failed after parsing", params)
+		}
+	}
+}
+
+func TestMarshalPKCS8PrivateKeyMT(t *testing.T) {
+	seed, err := hex.DecodeString("5F706A93A124CB56BE67FF5F1133FD7EB62A36CB182AEF97B9559746DF3F1936")
+	if err != nil {
+		t.Fatal(err)
+	}
+	privKey, pubKey, err := NewXMSSMTKeyPair(6, 2, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("Test Nachricht")
+	for i := 0; i < 9; i++ {
+		if _, err := privKey.Sign(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	derData, err := MarshalPKCS8PrivateKeyMT(privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ParsePKCS8PrivateKey(derData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privKey2 := key.(*PrivateKeyMT)
+	if privKey2.XMSSMTParameters != privKey.XMSSMTParameters {
+		t.Errorf("parameters are different: %s", privKey2.XMSSMTParameters)
+	}
+	if privKey2.index != 9 {
+		t.Errorf("Index is different: %d", privKey2.index)
+	}
+	sig, err := privKey2.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig2, err := privKey.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sig, sig2) {
+		t.Error("signature after parsing is different")
+	}
+	if !pubKey.Verify(sig, msg) {
+		t.Error("verification failed after parsing")
+	}
+}
+
+func TestMarshalPKIXPublicKey(t *testing.T) {
+	pemCert, _ := pem.Decode([]byte(certPEM))
+	cert, err := x509.ParseCertificate(pemCert.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ParsePKIXPublicKey(cert.RawSubjectPublicKeyInfo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := MarshalPKIXPublicKey(key.(*PublicKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(der, cert.RawSubjectPublicKeyInfo) {
+		t.Errorf("PKIX public key is different from BouncyCastle: %X", der)
+	}
+
+	pemKey, _ := pem.Decode([]byte(privateKey))
+	priv, err := ParsePKCS8PrivateKey(pemKey.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err = MarshalPKIXPublicKey(&priv.(*PrivateKey).PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(der, cert.RawSubjectPublicKeyInfo) {
+		t.Errorf("PKIX public key of private key is different from BouncyCastle: %X", der)
+	}
+
+	seed, err := hex.DecodeString("5F706A93A124CB56BE67FF5F1133FD7EB62A36CB182AEF97B9559746DF3F1936")
+	if err != nil {
+		t.Fatal(err)
+	}
+	privKey, pubKey, err := NewXMSSKeyPairFromParams(&XMSSParameters{Func: SHAKE, N: 32, Height: 4}, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err = MarshalPKIXPublicKey(pubKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err = ParsePKIXPublicKey(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("Test Nachricht")
+	if !key.(*PublicKey).Verify(mustSign(t, privKey, msg), msg) {
+		t.Error("verification failed after parsing")
+	}
+}
+
+func TestMarshalPKIXPublicKeyMT(t *testing.T) {
+	seed, err := hex.DecodeString("5F706A93A124CB56BE67FF5F1133FD7EB62A36CB182AEF97B9559746DF3F1936")
+	if err != nil {
+		t.Fatal(err)
+	}
+	privKey, pubKey, err := NewXMSSMTKeyPair(6, 3, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := MarshalPKIXPublicKeyMT(pubKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ParsePKIXPublicKey(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubKey2 := key.(*PublicKeyMT)
+	if pubKey2.XMSSMTParameters != pubKey.XMSSMTParameters {
+		t.Errorf("parameters are different: %s", pubKey2.XMSSMTParameters)
+	}
+	msg := []byte("Test Nachricht")
+	sig, err := privKey.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pubKey2.Verify(sig, msg) {
+		t.Error("verification failed after parsing")
+	}
+	der2, err := MarshalPKIXPublicKeyMT(pubKey2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(der, der2) {
+		t.Error("PKIX public key is different after parsing")
+	}
+}
+
+func TestPEM(t *testing.T) {
+	key, err := DecodePEM([]byte(privateKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv := key.(*PrivateKey)
+	data, err := EncodePEM(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err = DecodePEM(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := key.(*PublicKey)
+	if !bytes.Equal(pub.root, priv.root) {
+		t.Errorf("root is different: %X", pub.root)
+	}
+
+	msg := []byte("Test Nachricht")
+	data = EncodeSignaturePEM(mustSign(t, priv, msg))
+	sig, err := DecodeSignaturePEM(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pub.Verify(sig, msg) {
+		t.Error("verification failed after decoding")
+	}
+	if _, err := DecodePEM(data); err == nil {
+		t.Error("signature must not be decoded as a key")
+	}
+	if _, err := DecodeSignaturePEM(append(data, data...)); err != ErrTrailingData {
+		t.Errorf("trailing data must be detected: %v", err)
+	}
+	if _, err := DecodeSignaturePEM(append([]byte("\n\t"), append(data, "\n"...)...)); err != nil {
+		t.Errorf("white space around the block must be accepted: %v", err)
+	}
+	if _, err := DecodeSignaturePEM(append([]byte("junk\n"), data...)); err == nil {
+		t.Error("data before the block must be detected")
+	}
+	if _, err := DecodePEM(append([]byte(privateKey), "junk"...)); err != ErrTrailingData {
+		t.Errorf("trailing data after a key must be detected: %v", err)
+	}
+
+	seed, err := hex.DecodeString("5F706A93A124CB56BE67FF5F1133FD7EB62A36CB182AEF97B9559746DF3F1936")
+	if err != nil {
+		t.Fatal(err)
+	}
+	privMT, _, err := NewXMSSMTKeyPair(4, 2, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err = EncodePEM(privMT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err = DecodePEM(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := key.(*PrivateKeyMT); !ok {
+		t.Errorf("XMSS^MT private key is decoded as %T", key)
+	}
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = DecodePEM(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	if _, ok := err.(UnsupportedAlgorithmError); !ok {
+		t.Errorf("ECDSA public key must be rejected as unsupported: %v", err)
+	}
+}
@@ -28,12 +28,19 @@ import (
 
 const (
 	w     = 16
-	wlen1 = 64
 	wlen2 = 3
-	wlen  = wlen1 + wlen2
-	n     = 32
 )
 
+//wlen1 returns the number of base-w digits of a n bytes message.
+func wlen1(n int) int {
+	return 2 * n
+}
+
+//wlen returns the number of chains in WOTS+ with n bytes hashes.
+func wlen(n int) int {
+	return wlen1(n) + wlen2
+}
+
 func base16(x []byte, basew []uint8) {
 	for i := 0; i < len(basew); i++ {
 		if i&1 != 0 {
@@ -48,46 +55,65 @@ type wotsPrivKey [][]byte
 type wotsPubKey [][]byte
 type wotsSig [][]byte
 
-func chain(x []byte, start, step byte, p *prf, addrs addr, out []byte) {
+func chain(s *scratch, x []byte, start, step byte, p *prf, addrs addr, out []byte) {
 	copy(out, x)
-	key := make([]byte, 32)
-	bm := make([]byte, 32)
-	xor := make([]byte, 32)
+	n := len(x)
+	key := s.key[:n]
+	bm := s.bm[0][:n]
+	xor := s.xor[0][:n]
 	for i := byte(0); i < step; i++ {
 		addrs.set(adrHash, uint32(start+i))
 		addrs.set(adrKM, 0)
-		p.sum(addrs, key)
+		p.sum(s, addrs, key)
 		addrs.set(adrKM, 1)
-		p.sum(addrs, bm)
+		p.sum(s, addrs, bm)
 		xorWords(xor, out, bm)
-		hashF(key, xor, out)
+		p.hash.hashF(s, key, xor, out)
 	}
 }
 
-func (priv wotsPrivKey) newWotsPubKey(p *prf, addrs addr, pubkey wotsPubKey) {
+func (priv wotsPrivKey) newWotsPubKey(s *scratch, p *prf, addrs addr, pubkey wotsPubKey) {
 	for i := 0; i < len(pubkey); i++ {
 		addrs.set(adrChain, uint32(i))
-		chain(priv[i], 0, w-1, p, addrs, pubkey[i])
+		chain(s, priv[i], 0, w-1, p, addrs, pubkey[i])
 	}
 }
 
-func goChain(addrs addr, fchain func(i int, a addr)) {
+//wotsChain is the part of a WOTS+ chain computed by chain, steps steps
+//from in at start, written to out.
+type wotsChain struct {
+	in, out      []byte
+	start, steps byte
+}
+
+//goChain computes the chains of cs, where cs[i] is the chain i of addrs, on
+//procs goroutines, or on the calling goroutine if procs is 1. The first
+//goroutine uses s and the others a scratch of scratches.
+func goChain(s *scratch, procs int, addrs addr, p *prf, cs []wotsChain) {
+	if procs <= 1 {
+		seqChain(s, addrs, p, cs, 0)
+		return
+	}
 	var wg sync.WaitGroup
-	ncpu := runtime.GOMAXPROCS(-1)
-	nitem := wlen/ncpu + 1
+	ncpu := procs
+	nitem := len(cs)/ncpu + 1
 	for i := 0; i < ncpu; i++ {
 		wg.Add(1)
 		go func(i int) {
 			start := i * nitem
 			end := start + nitem
-			if end > wlen {
-				end = wlen
+			if end > len(cs) {
+				end = len(cs)
+			}
+			sc := s
+			if i > 0 {
+				sc = scratches.Get().(*scratch)
+			}
+			if start < end {
+				seqChain(sc, addrs, p, cs[start:end], uint32(start))
 			}
-			a := make(addr, 32)
-			copy(a, addrs)
-			for j := start; j < end; j++ {
-				a.set(adrChain, uint32(j))
-				fchain(j, a)
+			if i > 0 {
+				scratches.Put(sc)
 			}
 			wg.Done()
 		}(i)
@@ -95,10 +121,28 @@ func goChain(addrs addr, fchain func(i int, a addr)) {
 	wg.Wait()
 }
 
-func (priv wotsPrivKey) goNewWotsPubKey(p *prf, addrs addr, pubkey wotsPubKey) {
-	goChain(addrs, func(i int, a addr) {
-		chain(priv[i], 0, w-1, p, a, pubkey[i])
-	})
+//seqChain is goChain without goroutines for the chains cs, where cs[i] is
+//the chain first+i. Several chains are advanced in lockstep if the CPU can
+//hash them at once.
+func seqChain(s *scratch, addrs addr, p *prf, cs []wotsChain, first uint32) {
+	if useLanes && p.block1 != nil && len(cs) > 1 {
+		chainLanes(s, addrs, p, cs, first)
+		return
+	}
+	a := addr(s.adr[:])
+	copy(a, addrs)
+	for j := range cs {
+		a.set(adrChain, first+uint32(j))
+		chain(s, cs[j].in, cs[j].start, cs[j].steps, p, a, cs[j].out)
+	}
+}
+
+func (priv wotsPrivKey) goNewWotsPubKey(s *scratch, p *prf, addrs addr, pubkey wotsPubKey, procs int) {
+	cs := s.chains(len(priv))
+	for i := range cs {
+		cs[i] = wotsChain{in: priv[i], out: pubkey[i], steps: w - 1}
+	}
+	goChain(s, procs, addrs, p, cs)
 }
 
 const (
@@ -106,41 +150,41 @@ const (
 	toPubkey
 )
 
-func nchain(in [][]byte, m []byte, p *prf, addrs addr, typee int) [][]byte {
-	out := make([][]byte, wlen)
-	for i := range out {
-		out[i] = make([]byte, n)
-	}
-	msg := make([]byte, wlen)
-	base16(m, msg[:wlen1])
+//nchain computes the chains of a WOTS+ signature of m from the private key
+//in (typee toSig), or of the public key from the signature in (toPubkey),
+//writing them to out.
+func nchain(s *scratch, in [][]byte, m []byte, p *prf, addrs addr, typee int, procs int, out [][]byte) {
+	n := p.hash.size()
+	l1 := wlen1(n)
+	msg := s.digits[:wlen(n)]
+	base16(m, msg[:l1])
 	var csum uint16
-	for _, mm := range msg[:wlen1] {
+	for _, mm := range msg[:l1] {
 		csum += w - 1 - uint16(mm)
 	}
 	csum <<= 4
-	tmp := []byte{
+	tmp := [2]byte{
 		byte((csum & 0xff00) >> 8),
 		byte((csum & 0x00ff)),
 	}
-	base16(tmp, msg[wlen1:])
-	if typee == toSig {
-		goChain(addrs, func(i int, a addr) {
-			chain(in[i], 0, msg[i], p, a, out[i])
-		})
-	} else {
-		goChain(addrs, func(i int, a addr) {
-			chain(in[i], msg[i], w-1-msg[i], p, a, out[i])
-		})
+	base16(tmp[:], msg[l1:])
+	cs := s.chains(len(out))
+	for i := range cs {
+		if typee == toSig {
+			cs[i] = wotsChain{in: in[i], out: out[i], start: 0, steps: msg[i]}
+		} else {
+			cs[i] = wotsChain{in: in[i], out: out[i], start: msg[i], steps: w - 1 - msg[i]}
+		}
 	}
-	return out
+	goChain(s, procs, addrs, p, cs)
 }
 
-func (priv wotsPrivKey) sign(m []byte, p *prf, addrs addr) wotsSig {
-	return nchain(priv, m, p, addrs, toSig)
+func (priv wotsPrivKey) sign(s *scratch, m []byte, p *prf, addrs addr, procs int, sig wotsSig) {
+	nchain(s, priv, m, p, addrs, toSig, procs, sig)
 }
 
-func (sig wotsSig) pubkey(m []byte, p *prf, addrs addr) wotsPubKey {
-	return nchain(sig, m, p, addrs, toPubkey)
+func (sig wotsSig) pubkey(s *scratch, m []byte, p *prf, addrs addr, procs int, pk wotsPubKey) {
+	nchain(s, sig, m, p, addrs, toPubkey, procs, pk)
 }
 
 //codes below is from https://golang.org/src/crypto/cipher/xor.go
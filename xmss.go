@@ -25,14 +25,18 @@ import (
 	"crypto"
 	"crypto/hmac"
 	"crypto/sha256"
+	"crypto/sha512"
 	"encoding/binary"
 	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
 )
 
-type XMSSParameters struct {
-	//oid
-	Height uint32
-}
+// ErrKeyExhausted is returned by Sign of PrivateKey and PrivateKeyMT when
+// all one-time keys are used.
+var ErrKeyExhausted = errors.New("xmss: all one-time keys are used")
 
 // XMSS private key
 type PrivateKey struct {
@@ -40,6 +44,14 @@ type PrivateKey struct {
 	msgPRF  *prf    // prf for randomization of message digest
 	wotsPRF *prf    // prf for generating WOTS+ private keys
 	m       *merkle // state
+	bdsK    uint32  // BDS parameter k of the state
+
+	lowWatermark uint64                 // number of remaining keys to call onLow at
+	onLow        func(remaining uint64) // called by Sign at or below lowWatermark
+	store        StateStore             // records used indices, optional
+	reserved     uint64                 // indices below are reserved in store
+	end          uint64                 // index after the last usable key, 0 for all
+	sc           *scratch               // working memory of Sign, allocated on demand
 }
 
 type PrivateKeyExport struct {
@@ -47,6 +59,8 @@ type PrivateKeyExport struct {
 	Index         uint32 // index of next unused WOTS+ private key
 	SecretKeyPRF  []byte // seed for randomization of message digest
 	SecretKeySeed []byte // seed for generating WOTS+ private keys
+	BdsState      []byte // traversal state at Index, optional
+	End           uint64 // index after the last usable WOTS+ private key, 0 for all
 }
 
 // XMSS public key
@@ -54,6 +68,7 @@ type PublicKey struct {
 	XMSSParameters
 	publicSeed []byte // publicSeed for randomization of hashes
 	root       []byte // root of merkle tree
+	procs      int    // number of goroutines, 0 for GOMAXPROCS
 }
 
 type PublicKeyExport struct {
@@ -62,92 +77,294 @@ type PublicKeyExport struct {
 	Root       []byte // root of merkle tree
 }
 
+// NewXMSSKeyPair creates a key pair with SHA2 and n = 32 for height. The
+// seeds of the key are derived from privateSeed by DerivationHMAC; see
+// GenerateKey and DeriveKey for the seed layout of RFC 8391.
 func NewXMSSKeyPair(height uint32, privateSeed []byte) (*PrivateKey, *PublicKey) {
-	mac := hmac.New(sha256.New, privateSeed)
+	secretKeySeed, secretKeyPRF, publicSeed := deriveSeeds(privateSeed, 32)
+	return newXMSSKeyPair(defaultXMSSParameters(height), secretKeySeed, secretKeyPRF, publicSeed, 0, 0)
+}
+
+// NewXMSSKeyPairFromParams creates a key pair for the given parameter set.
+// It fails if the parameter set is not supported.
+func NewXMSSKeyPairFromParams(params *XMSSParameters, privateSeed []byte) (*PrivateKey, *PublicKey, error) {
+	if err := params.validate(); err != nil {
+		return nil, nil, err
+	}
+	secretKeySeed, secretKeyPRF, publicSeed := deriveSeeds(privateSeed, params.N)
+	priv, pub := newXMSSKeyPair(*params, secretKeySeed, secretKeyPRF, publicSeed, 0, 0)
+	return priv, pub, nil
+}
+
+// NewXMSSKeyPairWithBDS is NewXMSSKeyPairFromParams with the parameter k of
+// the BDS tree traversal, which trades memory for signing time. The private
+// key retains the 2^k-k-1 right nodes of the top k levels of the tree, and
+// each signature computes at most (h-k)/2 leaves of the tree in advance.
+// h-k must be even and not negative. NewXMSSKeyPairFromParams uses the
+// smallest valid k, which is 0 for even heights and 1 for odd ones.
+func NewXMSSKeyPairWithBDS(params *XMSSParameters, privateSeed []byte, k uint32) (*PrivateKey, *PublicKey, error) {
+	if err := params.validate(); err != nil {
+		return nil, nil, err
+	}
+	if err := validateBDSK(params.Height, k); err != nil {
+		return nil, nil, err
+	}
+	secretKeySeed, secretKeyPRF, publicSeed := deriveSeeds(privateSeed, params.N)
+	priv, pub := newXMSSKeyPairAt(*params, secretKeySeed, secretKeyPRF, publicSeed, 0, 0, 0, k)
+	return priv, pub, nil
+}
+
+// NewXMSSKeyPairWithParams creates a key pair with SHA2 and n = 32 for height
+// from the seeds SK_SEED, SK_PRF and PUB_SEED of RFC 8391 for the tree at
+// layer and tree of a hypertree. It fails if height is not supported or a
+// seed is not 32 bytes long.
+func NewXMSSKeyPairWithParams(height uint32, secretKeySeed, secretKeyPRF, publicSeed []byte, layer uint32, tree uint64) (*PrivateKey, *PublicKey, error) {
+	params := defaultXMSSParameters(height)
+	if err := params.validate(); err != nil {
+		return nil, nil, err
+	}
+	if err := validateSeeds(params.N, secretKeySeed, secretKeyPRF, publicSeed); err != nil {
+		return nil, nil, err
+	}
+	priv, pub := newXMSSKeyPair(params, secretKeySeed, secretKeyPRF, publicSeed, layer, tree)
+	return priv, pub, nil
+}
+
+func newXMSSKeyPair(params XMSSParameters, secretKeySeed, secretKeyPRF, publicSeed []byte, layer uint32, tree uint64) (*PrivateKey, *PublicKey) {
+	return newXMSSKeyPairAt(params, secretKeySeed, secretKeyPRF, publicSeed, layer, tree, 0, defaultBDSK(params.Height))
+}
+
+//newXMSSKeyPairAt is newXMSSKeyPair with the traversal state initialized
+//at leaf with the BDS parameter k.
+func newXMSSKeyPairAt(params XMSSParameters, secretKeySeed, secretKeyPRF, publicSeed []byte, layer uint32, tree uint64, leaf, k uint32) (*PrivateKey, *PublicKey) {
+	privateKey, publicKey := newXMSSKey(params, secretKeySeed, secretKeyPRF, publicSeed, k)
+	copy(privateKey.root, privateKey.initMerkleAt(leaf, layer, tree))
+	return privateKey, publicKey
+}
+
+//newXMSSKey returns a key pair whose root is not computed yet and whose
+//private key has no traversal state.
+func newXMSSKey(params XMSSParameters, secretKeySeed, secretKeyPRF, publicSeed []byte, k uint32) (*PrivateKey, *PublicKey) {
+	publicKey := PublicKey{
+		XMSSParameters: params,
+		root:           make([]byte, params.N),
+		publicSeed:     publicSeed,
+	}
+	privateKey := PrivateKey{
+		PublicKey: publicKey,
+		msgPRF:    params.hash().newPRF(secretKeyPRF),
+		wotsPRF:   params.hash().newPRF(secretKeySeed),
+		bdsK:      k,
+	}
+	return &privateKey, &publicKey
+}
+
+// deriveSeeds derives the n bytes SK_SEED, SK_PRF and PUB_SEED from
+// privateSeed as DerivationHMAC.
+func deriveSeeds(privateSeed []byte, n uint32) (secretKeySeed, secretKeyPRF, publicSeed []byte) {
+	h := sha256.New
+	if n == 64 {
+		h = sha512.New
+	}
+	mac := hmac.New(h, privateSeed)
 	if _, err := mac.Write([]byte{1}); err != nil {
 		panic(err)
 	}
-	secretKeySeed := mac.Sum(nil)
+	secretKeySeed = mac.Sum(nil)
 	mac.Reset()
 	if _, err := mac.Write([]byte{2}); err != nil {
 		panic(err)
 	}
-	secretKeyPRF := mac.Sum(nil)
+	secretKeyPRF = mac.Sum(nil)
 	mac.Reset()
 	if _, err := mac.Write([]byte{3}); err != nil {
 		panic(err)
 	}
-	publicSeed := mac.Sum(nil)
-	return NewXMSSKeyPairWithParams(height, secretKeySeed, secretKeyPRF, publicSeed, 0, 0)
+	publicSeed = mac.Sum(nil)
+	return
 }
 
-func NewXMSSKeyPairWithParams(height uint32, secretKeySeed, secretKeyPRF, publicSeed []byte, layer uint32, tree uint64) (*PrivateKey, *PublicKey) {
-	publicKey := PublicKey{
-		XMSSParameters: XMSSParameters{Height: height},
-		root:           make([]byte, 32),
-		publicSeed:     publicSeed,
+// Public() returns the public key corresponding to priv
+func (priv *PrivateKey) Public() crypto.PublicKey {
+	return &priv.PublicKey
+}
+
+// Sign signs digest with priv and makes PrivateKey a crypto.Signer.
+//
+// XMSS hashes the message itself with H_msg, so no pre-hashing is needed:
+// if opts is nil or opts.HashFunc() is zero, digest is the message itself.
+// Otherwise digest must be a hash of the message computed with
+// opts.HashFunc(), and Verify must be called with that digest, not with
+// the message. rand is ignored because XMSS signatures are deterministic.
+//
+// Sign returns ErrKeyExhausted when all one-time keys of priv are used.
+// With a StateStore, the index is recorded before the signature is made.
+func (priv *PrivateKey) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if opts != nil && opts.HashFunc() != 0 && len(digest) != opts.HashFunc().Size() {
+		return nil, errors.New("xmss: digest length does not match the hash function")
 	}
-	privateKey := PrivateKey{
-		PublicKey: publicKey,
-		msgPRF:    newPRF(secretKeyPRF),
-		wotsPRF:   newPRF(secretKeySeed),
+	s := priv.scratch()
+	s.rd.Reset(digest)
+	sig, err := priv.SignReader(&s.rd)
+	s.rd.Reset(nil)
+	return sig, err
+}
+
+// SignReader is Sign for the message read from m until EOF. The message is
+// hashed while it is read, so it need not fit into memory. If reading m
+// fails, the error is returned and no one-time key is used.
+func (priv *PrivateKey) SignReader(m io.Reader) ([]byte, error) {
+	if priv.Remaining() == 0 {
+		return nil, ErrKeyExhausted
+	}
+	if err := priv.reserve(); err != nil {
+		return nil, err
+	}
+	sig, err := priv.sign(m)
+	if err != nil {
+		return nil, err
+	}
+	if remaining := priv.Remaining(); priv.onLow != nil && remaining <= priv.lowWatermark {
+		priv.onLow(remaining)
 	}
-	privateKey.initMerkle(height, layer, tree)
+	return sig, nil
+}
 
-	return &privateKey, &publicKey
+// Index returns the index of the next unused one-time key.
+func (priv *PrivateKey) Index() uint32 {
+	return priv.m.leaf
 }
 
-// Public() returns the public key corresponding to priv
-func (priv *PrivateKey) Public() crypto.PublicKey {
-	return &priv.PublicKey
+// Remaining returns the number of one-time keys which can still be used.
+func (priv *PrivateKey) Remaining() uint64 {
+	if uint64(priv.m.leaf) >= priv.limit() {
+		return 0
+	}
+	return priv.limit() - uint64(priv.m.leaf)
 }
 
-func (priv *PrivateKey) Sign(msg []byte) ([]byte) {
-	index := make([]byte, 32)
-	binary.BigEndian.PutUint32(index[28:], priv.m.leaf)
-	r := make([]byte, 32*3)
-	priv.msgPRF.sum(index, r)
-	copy(r[32:], priv.root)
-	copy(r[64:], index)
-	hmsg := hashMsg(r, msg)
-	sigBody := priv.createSignatureBody(hmsg)
-	sig := &xmssSig{
-		index:       priv.m.leaf,
-		r:           r[:32],
-		xmssSigBody: sigBody,
-	}
-	result := sig.bytes()
-	priv.traverse()
-	return result
+//limit returns the index after the last one-time key priv may use.
+func (priv *PrivateKey) limit() uint64 {
+	if priv.end == 0 {
+		return 1 << priv.Height
+	}
+	return priv.end
+}
+
+// Delegate moves the next n unused one-time keys of priv to a new private
+// key with the same public key, which signs only with the keys in its
+// range. priv skips the range, so keys delegated by successive calls never
+// overlap. If priv has a StateStore, the range is reserved in it first.
+//
+// The range is enforced by this package only: the export of the new key
+// still contains the secret seeds, from which every one-time key can be
+// derived.
+func (priv *PrivateKey) Delegate(n uint32) (*PrivateKey, error) {
+	if n == 0 || uint64(n) > priv.Remaining() {
+		return nil, fmt.Errorf("xmss: cannot delegate %d of %d remaining keys", n, priv.Remaining())
+	}
+	if priv.store != nil {
+		if err := priv.Reserve(uint64(n)); err != nil {
+			return nil, err
+		}
+	}
+	start := priv.m.leaf
+	end := uint64(start) + uint64(n)
+	sub := &PrivateKey{
+		PublicKey: priv.PublicKey,
+		msgPRF:    priv.hash().newPRF(priv.msgPRF.seed),
+		wotsPRF:   priv.hash().newPRF(priv.wotsPRF.seed),
+		bdsK:      priv.bdsK,
+		end:       end,
+	}
+	sub.initMerkleAt(start, 0, 0)
+	priv.skipTo(uint32(end))
+	return sub, nil
 }
 
-func (priv *PrivateKey) createSignatureBody(hmsg []byte) *xmssSigBody {
-	wsk := make(wotsPrivKey, wlen)
-	for i := range wsk {
-		wsk[i] = make([]byte, 32)
+// SetConcurrency sets the number of goroutines which compute the tree and
+// the WOTS+ chains for the key, e.g. when signing, verifying or rebuilding
+// the traversal state. 1 computes everything on the calling goroutine, and
+// 0 or less restores the default, GOMAXPROCS.
+func (pub *PublicKey) SetConcurrency(n int) {
+	if n < 0 {
+		n = 0
 	}
-	addrs := make(addr, 32)
-	addrs.set(adrLayer, priv.m.layer)
-	addrs.setTree(priv.m.tree)
-	addrs.set(adrOTS, priv.m.leaf)
-	priv.newWotsPrivKey(addrs, wsk)
-	pubPRF := newPRF(priv.publicSeed)
-	sig := wsk.sign(hmsg, pubPRF, addrs)
-	return &xmssSigBody{
-		sig:  sig,
-		auth: priv.m.auth,
+	pub.procs = n
+}
+
+//concurrency returns the number of goroutines for the key.
+func (pub *PublicKey) concurrency() int {
+	if pub.procs > 0 {
+		return pub.procs
 	}
+	return runtime.GOMAXPROCS(-1)
+}
+
+// SetLowWatermark makes Sign call f with the number of remaining one-time
+// keys after every signature that leaves at most n of them, so that the key
+// can be rotated before it is exhausted. f is called synchronously by Sign.
+// A nil f removes the callback.
+func (priv *PrivateKey) SetLowWatermark(n uint64, f func(remaining uint64)) {
+	priv.lowWatermark = n
+	priv.onLow = f
 }
 
-func (priv *PrivateKey) newWotsPrivKey(addrs addr, sk wotsPrivKey) {
-	s := make([]byte, 32)
-	priv.wotsPRF.sum(addrs, s)
-	p := newPRF(s)
+//sign signs the message read from m with the next unused one-time key.
+//Only the returned signature is allocated.
+func (priv *PrivateKey) sign(m io.Reader) ([]byte, error) {
+	s := priv.scratch()
+	n := int(priv.N)
+	r := s.r[:3*n]
+	priv.msgPRF.sumInt(s, priv.m.leaf, r[:n])
+	copy(r[n:], priv.root)
+	copy(r[2*n:], zero64)
+	binary.BigEndian.PutUint32(r[3*n-4:], priv.m.leaf)
+	hmsg, err := hashMsgReader(s, priv.hash(), r, m)
+	if err != nil {
+		return nil, err
+	}
+	sig := make([]byte, priv.SignatureSize())
+	binary.BigEndian.PutUint32(sig, priv.m.leaf)
+	copy(sig[4:], r[:n])
+	priv.createSignatureBody(hmsg, sig[4+n:])
+	priv.traverse()
+	return sig, nil
+}
+
+//createSignatureBody writes the WOTS+ signature of hmsg with the next unused
+//one-time key and its auth path to out.
+func (priv *PrivateKey) createSignatureBody(hmsg, out []byte) {
+	s := priv.scratch()
+	n := int(priv.N)
+	wsk, _ := s.wots(n)
+	addrs := s.otsAddr(priv.m.layer, priv.m.tree, priv.m.leaf)
+	priv.newWotsPrivKey(s, addrs, wsk)
+	pubPRF := s.prf(priv.hash(), priv.publicSeed)
+	sig := s.wotsSig(out, n)
+	wsk.sign(s, hmsg, pubPRF, addrs, priv.concurrency(), sig)
+	for i, a := range priv.m.auth {
+		copy(out[(len(sig)+i)*n:], a)
+	}
+}
+
+func (priv *PrivateKey) newWotsPrivKey(s *scratch, addrs addr, sk wotsPrivKey) {
+	seed := s.seed[:priv.N]
+	priv.wotsPRF.sum(s, addrs, seed)
+	p := s.seedPRF(priv.hash(), seed)
 	for i := range sk {
-		p.sumInt(uint32(i), sk[i])
+		p.sumInt(s, uint32(i), sk[i])
 	}
 }
 
+//scratch returns the working memory for the goroutine calling Sign.
+func (priv *PrivateKey) scratch() *scratch {
+	if priv.sc == nil {
+		priv.sc = newScratch()
+	}
+	return priv.sc
+}
+
 func (priv *PrivateKey) Export() *PrivateKeyExport {
 	return &PrivateKeyExport{
 		PublicKeyExport: PublicKeyExport{
@@ -158,34 +375,240 @@ func (priv *PrivateKey) Export() *PrivateKeyExport {
 		Index:         priv.m.leaf,
 		SecretKeyPRF:  priv.msgPRF.seed,
 		SecretKeySeed: priv.wotsPRF.seed,
+		BdsState:      priv.m.marshalBDS(priv.N),
+		End:           priv.end,
 	}
 }
 
-func (priv *PrivateKey) Import(key *PrivateKeyExport) {
-	priv.XMSSParameters = key.XMSSParameters
+// Import restores the key from its export. The traversal state is taken
+// from BdsState, or rebuilt from the seeds at Index directly if BdsState is
+// nil or not in the format of this package, which costs O(2^h) hashing
+// spread over all CPUs. An error is returned if BdsState is corrupted or
+// does not belong to the key, or if the parameters or the lengths of the
+// seeds and the root are invalid.
+func (priv *PrivateKey) Import(key *PrivateKeyExport) error {
+	params := key.params()
+	if err := params.validate(); err != nil {
+		return err
+	}
+	if err := validateSeeds(params.N, key.SecretKeySeed, key.SecretKeyPRF, key.PublicSeed, key.Root); err != nil {
+		return err
+	}
+	priv.XMSSParameters = params
 	priv.publicSeed = key.PublicSeed
 	priv.root = key.Root
-	priv.msgPRF = newPRF(key.SecretKeyPRF)
-	priv.wotsPRF = newPRF(key.SecretKeySeed)
-	priv.initMerkle(priv.Height, 0, 0)
-	for i := 0; i < int(key.Index); i++ {
-		priv.traverse()
+	priv.msgPRF = priv.hash().newPRF(key.SecretKeyPRF)
+	priv.wotsPRF = priv.hash().newPRF(key.SecretKeySeed)
+	priv.end = key.End
+	priv.bdsK = defaultBDSK(priv.Height)
+	if key.BdsState == nil {
+		priv.initMerkleAt(key.Index, 0, 0)
+		return nil
 	}
+	m, err := priv.unmarshalBDS(key.BdsState)
+	if err == errBDSFormat {
+		priv.initMerkleAt(key.Index, 0, 0)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if m.leaf != key.Index || m.layer != 0 || m.tree != 0 {
+		return errors.New("xmss: traversal state does not match the index")
+	}
+	priv.m = m
+	priv.bdsK = m.k
+	return nil
 }
 
 func (pub *PublicKey) Verify(bsig, msg []byte) bool {
-	sig, err := bytes2sig(bsig, byte(pub.XMSSParameters.Height))
-	if err != nil {
+	if pub.validate() != nil {
 		return false
 	}
-	prf := newPRF(pub.publicSeed)
-	r := make([]byte, 32*3)
+	s := scratches.Get().(*scratch)
+	ok := pub.verify(s, bsig, msg, s.prf(pub.hash(), pub.publicSeed), pub.concurrency())
+	scratches.Put(s)
+	return ok
+}
+
+// VerifySequential is Verify without spreading the WOTS+ chains over
+// goroutines. It has a higher throughput when many signatures are verified
+// concurrently, e.g. by separate goroutines or by BatchVerify.
+func (pub *PublicKey) VerifySequential(bsig, msg []byte) bool {
+	if pub.validate() != nil {
+		return false
+	}
+	s := scratches.Get().(*scratch)
+	ok := pub.verify(s, bsig, msg, s.prf(pub.hash(), pub.publicSeed), 1)
+	scratches.Put(s)
+	return ok
+}
+
+// BatchVerify verifies sigs[i] as the signature of msgs[i] for every i and
+// reports the results in the same order. The signatures are spread over
+// the goroutines of pub, or over opts.Concurrency goroutines if opts is not
+// nil, each of which verifies sequentially.
+// It panics if sigs and msgs differ in length.
+func (pub *PublicKey) BatchVerify(sigs, msgs [][]byte, opts *Options) []bool {
+	if len(sigs) != len(msgs) {
+		panic("xmss: numbers of signatures and messages differ")
+	}
+	ok := make([]bool, len(sigs))
+	if pub.validate() != nil {
+		return ok
+	}
+	prf := pub.hash().newPRF(pub.publicSeed)
+	spread(opts.concurrency(pub.concurrency()), len(sigs), func(i int) {
+		s := scratches.Get().(*scratch)
+		ok[i] = pub.verify(s, sigs[i], msgs[i], prf, 1)
+		scratches.Put(s)
+	})
+	return ok
+}
+
+// VerifyItem is a signature to be verified by VerifyBatch.
+type VerifyItem struct {
+	PublicKey *PublicKey
+	Message   []byte
+	Signature []byte
+}
+
+// VerifyBatch verifies the signatures of all items, which may belong to
+// different public keys, and reports the result of every item in the same
+// order. The PRF state of a public seed is computed once for all items of
+// the key, and the items are spread over opts.Concurrency workers, or over
+// GOMAXPROCS workers if opts is nil, each of which verifies sequentially.
+// The concurrency set on the public keys does not apply, because the items
+// may belong to many keys. Items without a valid public key fail.
+func VerifyBatch(items []VerifyItem, opts *Options) []bool {
+	ok := make([]bool, len(items))
+	prfs := make([]*prf, len(items))
+	keys := make(map[string]*prf)
+	for i, item := range items {
+		pub := item.PublicKey
+		if pub == nil || pub.validate() != nil {
+			continue
+		}
+		key := pub.XMSSParameters.String() + string(pub.publicSeed)
+		if keys[key] == nil {
+			keys[key] = pub.hash().newPRF(pub.publicSeed)
+		}
+		prfs[i] = keys[key]
+	}
+	spread(opts.concurrency(runtime.GOMAXPROCS(-1)), len(items), func(i int) {
+		if prfs[i] != nil {
+			s := scratches.Get().(*scratch)
+			ok[i] = items[i].PublicKey.verify(s, items[i].Signature, items[i].Message, prfs[i], 1)
+			scratches.Put(s)
+		}
+	})
+	return ok
+}
+
+//spread calls f for every i in [0, n) on procs workers, or on the calling
+//goroutine if procs is 1.
+func spread(procs int, n int, f func(i int)) {
+	if procs <= 1 {
+		for j := 0; j < n; j++ {
+			f(j)
+		}
+		return
+	}
+	items := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < procs; i++ {
+		wg.Add(1)
+		go func() {
+			for j := range items {
+				f(j)
+			}
+			wg.Done()
+		}()
+	}
+	for j := 0; j < n; j++ {
+		items <- j
+	}
+	close(items)
+	wg.Wait()
+}
+
+//verify verifies bsig with prf for the public seed, spreading the WOTS+
+//chains over procs goroutines. It does not allocate besides the scratch
+//of the other goroutines.
+func (pub *PublicKey) verify(s *scratch, bsig, msg []byte, prf *prf, procs int) bool {
+	s.rd.Reset(msg)
+	ok, _ := pub.verifyReader(s, bsig, &s.rd, prf, procs)
+	s.rd.Reset(nil)
+	return ok
+}
+
+// VerifyReader is Verify for the message read from m until EOF. The message
+// is hashed while it is read, so it need not fit into memory. An error is
+// returned only if reading m fails; m is not read if bsig is malformed.
+func (pub *PublicKey) VerifyReader(bsig []byte, m io.Reader) (bool, error) {
+	if pub.validate() != nil {
+		return false, nil
+	}
+	s := scratches.Get().(*scratch)
+	ok, err := pub.verifyReader(s, bsig, m, s.prf(pub.hash(), pub.publicSeed), pub.concurrency())
+	scratches.Put(s)
+	return ok, err
+}
+
+//verifyReader is verify for the message read from m.
+func (pub *PublicKey) verifyReader(s *scratch, bsig []byte, m io.Reader, prf *prf, procs int) (bool, error) {
+	sig := s.xmssSig()
+	if sig.setBytes(bsig, &pub.XMSSParameters) != nil {
+		return false, nil
+	}
+	n := int(pub.N)
+	r := s.r[:3*n]
 	copy(r, sig.r)
-	copy(r[32:], pub.root)
-	binary.BigEndian.PutUint32(r[64+28:], sig.index)
-	hmsg := hashMsg(r, msg)
-	root := rootFromSig(sig.index, hmsg, sig.xmssSigBody, prf, 0, 0)
-	return bytes.Equal(root, pub.root)
+	copy(r[n:], pub.root)
+	copy(r[2*n:], zero64)
+	binary.BigEndian.PutUint32(r[3*n-4:], sig.index)
+	hmsg, err := hashMsgReader(s, prf.hash, r, m)
+	if err != nil {
+		return false, err
+	}
+	root := rootFromSig(s, sig.index, hmsg, sig.xmssSigBody, prf, 0, 0, procs)
+	return bytes.Equal(root, pub.root), nil
+}
+
+// MarshalBinary encodes the public key in the format of RFC 8391,
+// OID || root || SEED.
+func (pub *PublicKey) MarshalBinary() ([]byte, error) {
+	if pub.OID == 0 {
+		return nil, fmt.Errorf("xmss: %s has no OID", pub.XMSSParameters)
+	}
+	n := int(pub.N)
+	b := make([]byte, pub.PublicKeySize())
+	binary.BigEndian.PutUint32(b, pub.OID)
+	copy(b[4:], pub.root)
+	copy(b[4+n:], pub.publicSeed)
+	return b, nil
+}
+
+// UnmarshalBinary decodes a public key in the format of RFC 8391.
+func (pub *PublicKey) UnmarshalBinary(b []byte) error {
+	if len(b) < 4 {
+		return errors.New("xmss: invalid length of public key")
+	}
+	params, err := XMSSParametersByOID(binary.BigEndian.Uint32(b))
+	if err != nil {
+		return err
+	}
+	if err := params.validate(); err != nil {
+		return err
+	}
+	if len(b) != params.PublicKeySize() {
+		return errors.New("xmss: invalid length of public key")
+	}
+	n := int(params.N)
+	pub.XMSSParameters = *params
+	pub.root = append([]byte(nil), b[4:4+n]...)
+	pub.publicSeed = append([]byte(nil), b[4+n:]...)
+	return nil
 }
 
 func (pub *PublicKey) Export() *PublicKeyExport {
@@ -197,38 +620,48 @@ func (pub *PublicKey) Export() *PublicKeyExport {
 }
 
 func (pub *PublicKey) Import(key *PublicKeyExport) {
-	pub.Height = key.Height
+	pub.XMSSParameters = key.params()
 	pub.publicSeed = key.PublicSeed
 	pub.root = key.Root
 }
 
-func randHash(left, right []byte, p *prf, addrs addr, out []byte) {
+// params returns the parameters of an export. Exports that only carry a
+// height get the SHA2 parameters with n = 32.
+func (key *PublicKeyExport) params() XMSSParameters {
+	if key.N == 0 {
+		return defaultXMSSParameters(key.Height)
+	}
+	return key.XMSSParameters
+}
+
+func randHash(s *scratch, left, right []byte, p *prf, addrs addr, out []byte) {
+	n := len(left)
 	addrs.set(adrKM, 0)
-	key := make([]byte, 32)
-	p.sum(addrs, key)
+	key := s.key[:n]
+	p.sum(s, addrs, key)
 	addrs.set(adrKM, 1)
-	bm0 := make([]byte, 32)
-	p.sum(addrs, bm0)
+	bm0 := s.bm[0][:n]
+	p.sum(s, addrs, bm0)
 	addrs.set(adrKM, 2)
-	bm1 := make([]byte, 32)
-	p.sum(addrs, bm1)
+	bm1 := s.bm[1][:n]
+	p.sum(s, addrs, bm1)
 
-	lxor := make([]byte, 32)
+	lxor := s.xor[0][:n]
 	xorWords(lxor, left, bm0)
-	rxor := make([]byte, 32)
+	rxor := s.xor[1][:n]
 	xorWords(rxor, right, bm1)
-	hashH(key, lxor, rxor, out)
+	p.hash.hashH(s, key, lxor, rxor, out)
 }
 
-func (pk wotsPubKey) ltree(p *prf, addrs addr) []byte {
+func (pk wotsPubKey) ltree(s *scratch, p *prf, addrs addr) []byte {
 	var height uint32
 	addrs.set(adrHeight, 0)
 	var l uint32
-	for l = wlen; l > 1; l = (l >> 1) + (l & 0x1) {
+	for l = uint32(len(pk)); l > 1; l = (l >> 1) + (l & 0x1) {
 		var i uint32
 		for i = 0; i < l>>1; i++ {
 			addrs.set(adrIndex, i)
-			randHash(pk[2*i], pk[2*i+1], p, addrs, pk[i])
+			randHash(s, pk[2*i], pk[2*i+1], p, addrs, pk[i])
 		}
 		if l&0x1 == 1 {
 			copy(pk[l>>1], pk[l-1])
@@ -251,7 +684,8 @@ type xmssSig struct {
 }
 
 func (x *xmssSig) bytes() []byte {
-	sigSize := 4 + n + wlen*n + len(x.auth)*n
+	n := len(x.r)
+	sigSize := 4 + n + len(x.sig)*n + len(x.auth)*n
 	sig := make([]byte, sigSize)
 	binary.BigEndian.PutUint32(sig, x.index)
 	copy(sig[4:], x.r)
@@ -261,6 +695,8 @@ func (x *xmssSig) bytes() []byte {
 }
 
 func (x *xmssSigBody) bytes() []byte {
+	n := len(x.sig[0])
+	wlen := len(x.sig)
 	sigSize := wlen*n + len(x.auth)*n
 	sig := make([]byte, sigSize)
 	for i, s := range x.sig {
@@ -272,53 +708,122 @@ func (x *xmssSigBody) bytes() []byte {
 	return sig
 }
 
-func bytes2sig(b []byte, h byte) (*xmssSig, error) {
-	height := (len(b) - (4 + n + wlen*n)) >> 5
-	if height != int(h) {
-		return nil, errors.New("invalid length of bytes")
-	}
-	body := bytes2sigBody(b[4+n:], height)
-	sig := &xmssSig{
-		index:       binary.BigEndian.Uint32(b),
-		r:           b[4 : 4+n],
-		xmssSigBody: body,
+func bytes2sig(b []byte, params *XMSSParameters) (*xmssSig, error) {
+	sig := &xmssSig{xmssSigBody: &xmssSigBody{}}
+	if err := sig.setBytes(b, params); err != nil {
+		return nil, err
 	}
 	return sig, nil
 }
 
-func bytes2sigBody(b []byte, height int) *xmssSigBody {
-	body := &xmssSigBody{
-		sig:  make([][]byte, wlen),
-		auth: make([][]byte, height),
+//setBytes sets x to the signature b, reusing the slices of x.
+func (x *xmssSig) setBytes(b []byte, params *XMSSParameters) error {
+	if len(b) != params.SignatureSize() {
+		return errors.New("invalid length of bytes")
+	}
+	if params.Height < 32 && binary.BigEndian.Uint32(b)>>params.Height != 0 {
+		return errors.New("index of signature is out of range")
+	}
+	n := int(params.N)
+	x.index = binary.BigEndian.Uint32(b)
+	x.r = b[4 : 4+n]
+	x.xmssSigBody.setBytes(b[4+n:], n, int(params.Height))
+	return nil
+}
+
+// Signature is a parsed XMSS signature, index || r || WOTS+ signature ||
+// auth path. The slices returned by its accessors share memory with it.
+type Signature struct {
+	XMSSParameters
+	sig *xmssSig
+}
+
+// ParseSignature parses the XMSS signature b for params.
+func ParseSignature(params *XMSSParameters, b []byte) (*Signature, error) {
+	if err := params.validate(); err != nil {
+		return nil, err
 	}
-	for i := 0; i < wlen; i++ {
-		body.sig[i] = b[i*n : (i+1)*n]
+	sig, err := bytes2sig(append([]byte(nil), b...), params)
+	if err != nil {
+		return nil, fmt.Errorf("xmss: %s", err)
 	}
-	for i := 0; i < height; i++ {
-		body.auth[i] = b[n*wlen+n*i : n*wlen+n*(i+1)]
+	return &Signature{
+		XMSSParameters: *params,
+		sig:            sig,
+	}, nil
+}
+
+// Index returns the index of the one-time key which made the signature.
+func (s *Signature) Index() uint32 {
+	return s.sig.index
+}
+
+// R returns the randomness r of the message hash.
+func (s *Signature) R() []byte {
+	return s.sig.r
+}
+
+// WOTS returns the chain values of the WOTS+ signature.
+func (s *Signature) WOTS() [][]byte {
+	return s.sig.sig
+}
+
+// AuthPath returns the nodes of the authentication path, from the leaf
+// level upwards.
+func (s *Signature) AuthPath() [][]byte {
+	return s.sig.auth
+}
+
+// MarshalBinary encodes the signature in the format of RFC 8391.
+func (s *Signature) MarshalBinary() ([]byte, error) {
+	return s.sig.bytes(), nil
+}
+
+//setBytes sets body to the signature body b, reusing the slices of body.
+func (body *xmssSigBody) setBytes(b []byte, n, height int) {
+	wlen := wlen(n)
+	body.sig = slices(body.sig, b, wlen, n)
+	body.auth = slices(body.auth, b[n*wlen:], height, n)
+}
+
+//slices returns the first count slices of n bytes of b, in the memory of
+//s if it is large enough.
+func slices(s [][]byte, b []byte, count, n int) [][]byte {
+	if cap(s) < count {
+		s = make([][]byte, count)
+	}
+	s = s[:count]
+	for i := range s {
+		s[i] = b[i*n : (i+1)*n]
 	}
-	return body
+	return s
 }
 
-func rootFromSig(idx uint32, hmsg []byte, body *xmssSigBody, prf *prf, layer uint32, tree uint64) []byte {
-	addrs := make(addr, 32)
-	addrs.set(adrLayer, layer)
-	addrs.setTree(tree)
-	addrs.set(adrOTS, idx)
-	pkOTS := body.sig.pubkey(hmsg, prf, addrs)
+//rootFromSig returns the root computed from the signature body of hmsg at
+//idx. It is stored in s.
+func rootFromSig(s *scratch, idx uint32, hmsg []byte, body *xmssSigBody, prf *prf, layer uint32, tree uint64, procs int) []byte {
+	addrs := s.otsAddr(layer, tree, idx)
+	_, pkOTS := s.wots(len(hmsg))
+	body.sig.pubkey(s, hmsg, prf, addrs, procs, pkOTS)
 	addrs.set(adrType, 1)
 	addrs.set(adrLtree, idx)
-	node0 := pkOTS.ltree(prf, addrs)
+	node0 := pkOTS.ltree(s, prf, addrs)
 	addrs.set(adrType, 2)
 	addrs.set(adrLtree, 0)
+	return rootFromAuth(s, idx, node0, body.auth, prf, addrs)
+}
+
+//rootFromAuth computes the root from the leaf node0 at idx and its auth path.
+//node0 is overwritten.
+func rootFromAuth(s *scratch, idx uint32, node0 []byte, auth [][]byte, prf *prf, addrs addr) []byte {
 	var k uint32
-	for k = 0; k < uint32(len(body.auth)); k++ {
+	for k = 0; k < uint32(len(auth)); k++ {
 		addrs.set(adrHeight, k)
 		addrs.set(adrIndex, idx>>1)
 		if idx&0x1 == 0 {
-			randHash(node0, body.auth[k], prf, addrs, node0)
+			randHash(s, node0, auth[k], prf, addrs, node0)
 		} else {
-			randHash(body.auth[k], node0, prf, addrs, node0)
+			randHash(s, auth[k], node0, prf, addrs, node0)
 		}
 		idx >>= 1
 	}
@@ -35,7 +35,7 @@ func TestXMSS2(t *testing.T) {
 	msg := []byte("This is a test for XMSS.")
 	var pre []byte
 	for i := 0; i < 1<<10; i++ {
-		sig := sk.Sign(msg)
+		sig := mustSign(t, sk, msg)
 		if !pk.Verify(sig, msg) {
 			t.Error("XMSS sig is incorrect")
 		}
@@ -53,7 +53,7 @@ func TestXMSS3(t *testing.T) {
 	seed := generateSeed()
 	sk, pk := NewXMSSKeyPair(10, seed)
 	msg := []byte("This is a test for XMSS.")
-	sig := sk.Sign(msg)
+	sig := mustSign(t, sk, msg)
 	msg[0] = 0
 	if pk.Verify(sig, msg) {
 		t.Error("XMSS sig is incorrect")
@@ -67,7 +67,7 @@ func TestXMSS4(t *testing.T) {
 	seed := generateSeed()
 	sk, pk := NewXMSSKeyPair(2, seed)
 	msg := []byte("This is a test for XMSS.")
-	sig := sk.Sign(msg)
+	sig := mustSign(t, sk, msg)
 	if !pk.Verify(sig, msg) {
 		t.Error("XMSS sig is incorrect")
 	}
@@ -83,7 +83,7 @@ func TestXMSS16(t *testing.T) {
 	seed := generateSeed()
 	sk, pk := NewXMSSKeyPair(16, seed)
 	msg := []byte("This is a test for XMSS height=16.")
-	sig := sk.Sign(msg)
+	sig := mustSign(t, sk, msg)
 	if !pk.Verify(sig, msg) {
 		t.Error("XMSS sig is incorrect")
 	}
@@ -107,7 +107,11 @@ func BenchmarkXMSS16Sign(b *testing.B) {
 	msg := []byte("This is a test for XMSS.")
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = sk.Sign(msg)
+		if _, err := sk.Sign(nil, msg, nil); err != nil {
+			b.StopTimer()
+			sk, _ = NewXMSSKeyPair(16, seed)
+			b.StartTimer()
+		}
 	}
 	runtime.GOMAXPROCS(npref)
 }
@@ -117,7 +121,7 @@ func BenchmarkXMSS16Veri(b *testing.B) {
 	seed := generateSeed()
 	sk, pk := NewXMSSKeyPair(16, seed)
 	msg := []byte("This is a test for XMSS.")
-	sig := sk.Sign(msg)
+	sig := mustSign(b, sk, msg)
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		pk.Verify(sig, msg)
@@ -150,9 +154,14 @@ func BenchmarkXMSS10Sign(b *testing.B) {
 	seed := generateSeed()
 	sk, _ := NewXMSSKeyPair(10, seed)
 	msg := []byte("This is a test for XMSS.")
+	b.ReportAllocs()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = sk.Sign(msg)
+		if _, err := sk.Sign(nil, msg, nil); err != nil {
+			b.StopTimer()
+			sk, _ = NewXMSSKeyPair(10, seed)
+			b.StartTimer()
+		}
 	}
 	runtime.GOMAXPROCS(npref)
 }
@@ -162,10 +171,95 @@ func BenchmarkXMSS10Veri(b *testing.B) {
 	seed := generateSeed()
 	sk, pk := NewXMSSKeyPair(10, seed)
 	msg := []byte("This is a test for XMSS.")
-	sig := sk.Sign(msg)
+	sig := mustSign(b, sk, msg)
+	b.ReportAllocs()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		pk.Verify(sig, msg)
 	}
 	runtime.GOMAXPROCS(npref)
 }
+func BenchmarkXMSS10VeriSequential(b *testing.B) {
+	n := numcpu.NumCPU()
+	npref := runtime.GOMAXPROCS(n)
+	seed := generateSeed()
+	sk, pk := NewXMSSKeyPair(10, seed)
+	msg := []byte("This is a test for XMSS.")
+	sig := mustSign(b, sk, msg)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pk.VerifySequential(sig, msg)
+	}
+	runtime.GOMAXPROCS(npref)
+}
+func BenchmarkXMSS10VeriConcurrent(b *testing.B) {
+	n := numcpu.NumCPU()
+	npref := runtime.GOMAXPROCS(n)
+	seed := generateSeed()
+	sk, pk := NewXMSSKeyPair(10, seed)
+	msg := []byte("This is a test for XMSS.")
+	sig := mustSign(b, sk, msg)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			pk.Verify(sig, msg)
+		}
+	})
+	runtime.GOMAXPROCS(npref)
+}
+func BenchmarkXMSS10VeriSequentialConcurrent(b *testing.B) {
+	n := numcpu.NumCPU()
+	npref := runtime.GOMAXPROCS(n)
+	seed := generateSeed()
+	sk, pk := NewXMSSKeyPair(10, seed)
+	msg := []byte("This is a test for XMSS.")
+	sig := mustSign(b, sk, msg)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			pk.VerifySequential(sig, msg)
+		}
+	})
+	runtime.GOMAXPROCS(npref)
+}
+func BenchmarkXMSS10BatchVerify(b *testing.B) {
+	n := numcpu.NumCPU()
+	npref := runtime.GOMAXPROCS(n)
+	seed := generateSeed()
+	sk, pk := NewXMSSKeyPair(10, seed)
+	msg := []byte("This is a test for XMSS.")
+	sig := mustSign(b, sk, msg)
+	sigs := make([][]byte, b.N)
+	msgs := make([][]byte, b.N)
+	for i := range sigs {
+		sigs[i], msgs[i] = sig, msg
+	}
+	b.ResetTimer()
+	pk.BatchVerify(sigs, msgs, nil)
+	runtime.GOMAXPROCS(npref)
+}
+func BenchmarkChain(b *testing.B) {
+	s := newScratch()
+	p := sha256Func.newPRF(generateSeed())
+	x := generateSeed()
+	out := make([]byte, 32)
+	adr := make(addr, 32)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		chain(s, x, 0, w-1, p, adr, out)
+	}
+}
+func BenchmarkRandHash(b *testing.B) {
+	s := newScratch()
+	p := sha256Func.newPRF(generateSeed())
+	left, right := generateSeed(), generateSeed()
+	out := make([]byte, 32)
+	adr := make(addr, 32)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		randHash(s, left, right, p, adr, out)
+	}
+}
@@ -20,281 +20,482 @@
 
 package xmss
 
-/*
-//PrivKeyMT is a private key of XMSS^MT.
-type PrivKeyMT struct {
-	index  uint64
-	merkle []*merkle
-	h      uint32
-	d      uint32
+import (
+	"bytes"
+	"crypto"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+)
+
+// XMSS^MT private key
+type PrivateKeyMT struct {
+	PublicKeyMT                 // public part (publicSeed, root, parameters)
+	index         uint64        // index of next unused WOTS+ private key
+	secretKeySeed []byte        // seed for generating WOTS+ private keys
+	msgPRF        *prf          // prf for randomization of message digest
+	trees         []*PrivateKey // XMSS tree of each layer, built on demand
+	sc            *scratch      // working memory of Sign, allocated on demand
 }
 
-//NewPrivKeyMT returns XMSS^MT private key.
-func NewPrivKeyMT(seed []byte, h, d uint32) (*PrivKeyMT, error) {
-	if h%d != 0 || h%20 != 0 || h == 0 || d == 0 || h/20 > 15 || d > 15 {
-		return nil, errors.New("invalid h or d")
-	}
-	p := PrivKeyMT{
-		merkle: make([]*merkle, d),
-		h:      h,
-		d:      d,
-	}
-	mac := hmac.New(sha256.New, seed)
-	if _, err := mac.Write([]byte{1}); err != nil {
-		panic(err)
+type PrivateKeyMTExport struct {
+	PublicKeyMTExport
+	Index         uint64 // index of next unused WOTS+ private key
+	SecretKeyPRF  []byte // seed for randomization of message digest
+	SecretKeySeed []byte // seed for generating WOTS+ private keys
+}
+
+// XMSS^MT public key
+type PublicKeyMT struct {
+	XMSSMTParameters
+	publicSeed []byte // publicSeed for randomization of hashes
+	root       []byte // root of the top layer tree
+	procs      int    // number of goroutines, 0 for GOMAXPROCS
+}
+
+type PublicKeyMTExport struct {
+	XMSSMTParameters
+	PublicSeed []byte // publicSeed for randomization of hashes
+	Root       []byte // root of the top layer tree
+}
+
+// NewXMSSMTKeyPair is the XMSS^MT counterpart of NewXMSSKeyPair.
+func NewXMSSMTKeyPair(height, layers uint32, privateSeed []byte) (*PrivateKeyMT, *PublicKeyMT, error) {
+	secretKeySeed, secretKeyPRF, publicSeed := deriveSeeds(privateSeed, 32)
+	return NewXMSSMTKeyPairWithParams(height, layers, secretKeySeed, secretKeyPRF, publicSeed)
+}
+
+// NewXMSSMTKeyPairFromParams creates a key pair for the given parameter set.
+// It fails if the parameter set is not supported.
+func NewXMSSMTKeyPairFromParams(params *XMSSMTParameters, privateSeed []byte) (*PrivateKeyMT, *PublicKeyMT, error) {
+	secretKeySeed, secretKeyPRF, publicSeed := deriveSeeds(privateSeed, params.N)
+	return newXMSSMTKeyPair(*params, secretKeySeed, secretKeyPRF, publicSeed)
+}
+
+func NewXMSSMTKeyPairWithParams(height, layers uint32, secretKeySeed, secretKeyPRF, publicSeed []byte) (*PrivateKeyMT, *PublicKeyMT, error) {
+	return newXMSSMTKeyPair(defaultXMSSMTParameters(height, layers), secretKeySeed, secretKeyPRF, publicSeed)
+}
+
+func newXMSSMTKeyPair(params XMSSMTParameters, secretKeySeed, secretKeyPRF, publicSeed []byte) (*PrivateKeyMT, *PublicKeyMT, error) {
+	if err := params.validate(); err != nil {
+		return nil, nil, err
 	}
-	wotsSeed := mac.Sum(nil)
-	mac.Reset()
-	if _, err := mac.Write([]byte{2}); err != nil {
-		panic(err)
+	if err := validateSeeds(params.N, secretKeySeed, secretKeyPRF, publicSeed); err != nil {
+		return nil, nil, err
 	}
-	msgSeed := mac.Sum(nil)
-	mac.Reset()
-	if _, err := mac.Write([]byte{3}); err != nil {
-		panic(err)
+	privateKey := newXMSSMTKey(params, secretKeySeed, secretKeyPRF, publicSeed)
+	privateKey.root = privateKey.tree(params.Layers-1, 0, 0).root
+	publicKey := privateKey.PublicKeyMT
+
+	return privateKey, &publicKey, nil
+}
+
+//newXMSSMTKey returns a private key without any tree and root.
+func newXMSSMTKey(params XMSSMTParameters, secretKeySeed, secretKeyPRF, publicSeed []byte) *PrivateKeyMT {
+	return &PrivateKeyMT{
+		PublicKeyMT: PublicKeyMT{
+			XMSSMTParameters: params,
+			publicSeed:       publicSeed,
+		},
+		secretKeySeed: secretKeySeed,
+		msgPRF:        params.hash().newPRF(secretKeyPRF),
+		trees:         make([]*PrivateKey, params.Layers),
 	}
-	pubSeed := mac.Sum(nil)
-	p.merkle[d-1] = newMerkle(h/d, wotsSeed, msgSeed, pubSeed, d-1, 0)
-	return &p, nil
 }
 
-//LeafNo returns the leaf no in xmss^mt.
-func (p *PrivKeyMT) LeafNo() uint64 {
-	return p.index
+// Public() returns the public key corresponding to priv
+func (priv *PrivateKeyMT) Public() crypto.PublicKey {
+	return &priv.PublicKeyMT
 }
 
-//SetLeafNo sets the leaf no in merkle and refresh authes..
-func (p *PrivKeyMT) SetLeafNo(n uint64) error {
-	if p.index < n {
-		return errors.New("should not set past index")
+// tree returns the XMSS tree at the given position in the hypertree, with
+// its traversal state advanced to leaf. Trees are only (re)built, directly
+// at leaf, when the requested tree differs from the cached one of that layer.
+func (priv *PrivateKeyMT) tree(layer uint32, tree uint64, leaf uint32) *PrivateKey {
+	t := priv.trees[layer]
+	if t == nil || t.m.tree != tree || t.m.leaf > leaf {
+		t = priv.newTree()
+		copy(t.root, t.initMerkleAt(leaf, layer, tree))
+		priv.trees[layer] = t
 	}
-	p.index = n
-	return nil
+	t.skipTo(leaf)
+	return t
 }
 
-//PublicKey returns public key (merkle root) of XMSS^MT
-func (p *PrivKeyMT) PublicKey() []byte {
-	priv := p.merkle[p.d-1].priv
-	key := make([]byte, 1+n+n)
-	key[0] = (byte(p.h / 20)) << 4
-	key[0] |= byte(p.d)
-	copy(key[1:], priv.root)
-	copy(key[1+n:], priv.pubPRF.seed)
-	return key
+//newTree returns an XMSS key of the hypertree without traversal state,
+//which computes on the goroutines of priv.
+func (priv *PrivateKeyMT) newTree() *PrivateKey {
+	t, _ := newXMSSKey(priv.XMSSMTParameters.tree(), priv.secretKeySeed, priv.msgPRF.seed, priv.publicSeed, defaultBDSK(priv.treeHeight()))
+	t.procs = priv.procs
+	return t
 }
 
-type xmssMTSig struct {
-	idx  uint64
-	r    []byte
-	sigs []*xmssSigBody
+// SetConcurrency sets the number of goroutines which compute the trees and
+// the WOTS+ chains for the key like PrivateKey.SetConcurrency.
+func (priv *PrivateKeyMT) SetConcurrency(n int) {
+	priv.PublicKeyMT.SetConcurrency(n)
+	for _, t := range priv.trees {
+		if t != nil {
+			t.procs = priv.procs
+		}
+	}
 }
 
-func (x *xmssMTSig) bytes() []byte {
-	d := len(x.sigs)
-	h := len(x.sigs[0].auth) * d
-	bytesPerLayer := (wlen + h/d) * n
-	sigSize := 8 + n + (wlen+h/d)*n*d
-	sig := make([]byte, sigSize)
-	binary.BigEndian.PutUint64(sig, x.idx)
-	copy(sig[8:], x.r)
-	for i, body := range x.sigs {
-		copy(sig[8+n+bytesPerLayer*i:], body.bytes())
-	}
-	return sig
+// Sign signs msg with priv. It returns ErrKeyExhausted when all one-time
+// keys of priv are used.
+func (priv *PrivateKeyMT) Sign(msg []byte) ([]byte, error) {
+	s := priv.scratch()
+	s.rd.Reset(msg)
+	sig, err := priv.SignReader(&s.rd)
+	s.rd.Reset(nil)
+	return sig, err
 }
 
-func bytes2MTsig(b []byte, d, h uint32) (*xmssMTSig, error) {
-	bytesPerLayer := (wlen + h/d) * n
-	sigSize := 8 + n + bytesPerLayer*d
-	if uint32(len(b)) != sigSize {
-		return nil, errors.New("invalid length of bytes")
+// SignReader is Sign for the message read from m until EOF. The message is
+// hashed while it is read, so it need not fit into memory. If reading m
+// fails, the error is returned and no one-time key is used.
+func (priv *PrivateKeyMT) SignReader(m io.Reader) ([]byte, error) {
+	if priv.exhausted() {
+		return nil, ErrKeyExhausted
 	}
-	sig := &xmssMTSig{
-		idx:  binary.BigEndian.Uint64(b),
-		r:    b[8 : 8+n],
-		sigs: make([]*xmssSigBody, d),
+	s := priv.scratch()
+	n := int(priv.N)
+	index := s.msg[:]
+	copy(index, zero64)
+	binary.BigEndian.PutUint64(index[24:], priv.index)
+	r := s.r[:3*n]
+	priv.msgPRF.sum(s, index, r[:n])
+	copy(r[n:], priv.root)
+	copy(r[2*n:], zero64)
+	binary.BigEndian.PutUint64(r[3*n-8:], priv.index)
+	hmsg, err := hashMsgReader(s, priv.hash(), r, m)
+	if err != nil {
+		return nil, err
 	}
-	for i := range sig.sigs {
-		start := 8 + n + uint32(i)*bytesPerLayer
-		sig.sigs[i] = bytes2sigBody(b[start:start+bytesPerLayer], int(h/d))
+	sig := make([]byte, priv.SignatureSize())
+	idxSize := priv.indexSize()
+	putIndex(sig[:idxSize], priv.index)
+	copy(sig[idxSize:], r[:n])
+
+	h := priv.treeHeight()
+	bytesPerLayer := (wlen(n) + int(h)) * n
+	mask := uint64(1)<<h - 1
+	idxTree := priv.index
+	node := hmsg
+	for j := uint32(0); j < priv.Layers; j++ {
+		idxLeaf := uint32(idxTree & mask)
+		idxTree >>= h
+		t := priv.tree(j, idxTree, idxLeaf)
+		start := idxSize + n + int(j)*bytesPerLayer
+		t.createSignatureBody(node, sig[start:start+bytesPerLayer])
+		node = t.root
 	}
+	priv.index++
 	return sig, nil
 }
 
-//Sign signs by XMSS with XMSS^MT.
-func (p *PrivKeyMT) Sign(msg []byte) []byte {
-	index := make([]byte, 32)
-	binary.BigEndian.PutUint64(index[24:], p.index)
-	mpriv := p.merkle[p.d-1].priv
-	r := make([]byte, 32*3)
-	mpriv.msgPRF.sum(index, r)
-	copy(r[32:], mpriv.root)
-	copy(r[64:], index)
-	hmsg := hashMsg(r, msg)
-	sig := &xmssMTSig{
-		idx:  p.index,
-		r:    r[:32],
-		sigs: make([]*xmssSigBody, p.d),
-	}
-	mask := uint64((1 << (p.h / p.d)) - 1)
-	idxTree := p.index >> (p.h / p.d)
-	idxLeaf := uint32(p.index & mask)
-	if p.merkle[0] == nil || p.merkle[0].tree != idxTree {
-		p.merkle[0] = newMerkle(p.h/p.d, mpriv.wotsPRF.seed, mpriv.msgPRF.seed, mpriv.pubPRF.seed, 0, idxTree)
-	}
-	for p.merkle[0].leaf < idxLeaf {
-		p.merkle[0].traverse()
-	}
-	sig.sigs[0] = p.merkle[0].sign(hmsg)
-	root := p.merkle[0].priv.root
-
-	for j := uint32(1); j < p.d; j++ {
-		idxLeaf := uint32(idxTree & mask)
-		idxTree = idxTree >> (p.h / p.d)
-		if p.merkle[j] == nil || p.merkle[j].tree != idxTree {
-			p.merkle[j] = newMerkle(p.h/p.d, mpriv.wotsPRF.seed, mpriv.msgPRF.seed, mpriv.pubPRF.seed, j, idxTree)
-		}
-		for p.merkle[j].leaf < idxLeaf {
-			p.merkle[j].traverse()
-		}
-		sig.sigs[j] = p.merkle[j].sign(root)
-		root = p.merkle[j].priv.root
+//scratch returns the working memory for the goroutine calling Sign.
+func (priv *PrivateKeyMT) scratch() *scratch {
+	if priv.sc == nil {
+		priv.sc = newScratch()
 	}
+	return priv.sc
+}
 
-	p.index++
-	return sig.bytes()
+//exhausted reports whether all one-time keys of priv are used. With a
+//height of 64 the last one-time key is never used, so that the index does
+//not wrap around.
+func (priv *PrivateKeyMT) exhausted() bool {
+	if priv.Height >= 64 {
+		return priv.index == ^uint64(0)
+	}
+	return priv.index >= 1<<priv.Height
 }
 
-//PublicKeyMT for xmss^MT
-type PublicKeyMT struct {
-	H    uint32
-	D    uint32
-	Root []byte
-	Seed []byte
+func (priv *PrivateKeyMT) Export() *PrivateKeyMTExport {
+	return &PrivateKeyMTExport{
+		PublicKeyMTExport: PublicKeyMTExport{
+			XMSSMTParameters: priv.XMSSMTParameters,
+			PublicSeed:       priv.publicSeed,
+			Root:             priv.root,
+		},
+		Index:         priv.index,
+		SecretKeyPRF:  priv.msgPRF.seed,
+		SecretKeySeed: priv.secretKeySeed,
+	}
 }
 
-//PublickeyMTHeader returns first 1 byte of public key of XMSS^MT
-func PublickeyMTHeader(h, d uint32) (byte, error) {
-	if h%d != 0 || h%20 != 0 || h == 0 || d == 0 ||
-		h/20 > 15 || d > 15 {
-		return 0, errors.New("invalid h or d")
+// Import restores the key from its export. The trees of all layers are
+// rebuilt lazily by the next call to Sign. It fails if the parameters or the
+// lengths of the seeds and the root are invalid.
+func (priv *PrivateKeyMT) Import(key *PrivateKeyMTExport) error {
+	params := key.params()
+	if err := params.validate(); err != nil {
+		return err
+	}
+	if err := validateSeeds(params.N, key.SecretKeySeed, key.SecretKeyPRF, key.PublicSeed, key.Root); err != nil {
+		return err
 	}
-	header := byte(h) / 20
-	header = (header << 4) | byte(d)
-	return header, nil
+	priv.XMSSMTParameters = params
+	priv.publicSeed = key.PublicSeed
+	priv.root = key.Root
+	priv.index = key.Index
+	priv.secretKeySeed = key.SecretKeySeed
+	priv.msgPRF = priv.hash().newPRF(key.SecretKeyPRF)
+	priv.trees = make([]*PrivateKey, key.Layers)
+	return nil
 }
 
-//Serialize returns serialized bytes of XMSS^MT PublicKey.
-func (p *PublicKeyMT) Serialize() ([]byte, error) {
-	var err error
-	key := make([]byte, 1+n+n)
-	key[0], err = PublickeyMTHeader(p.H, p.D)
-	if err != nil {
-		return nil, errors.New("invalid h or d")
-	}
-	key[0] = byte(p.H) / 20
-	key[0] = (key[0] << 4) | byte(p.D)
-	copy(key[1:], p.Root)
-	copy(key[1+n:], p.Seed)
-	return key, nil
-}
-
-//DeserializeMT deserialized bytes to XMSS^MT PublicKey.
-func DeserializeMT(key []byte) (*PublicKeyMT, error) {
-	if len(key) != 65 {
-		return nil, errors.New("invalid bytes length")
-	}
-	h := uint32(key[0] & 0xf0)
-	h = (h >> 4) * 20
-	d := uint32(key[0] & 0x0f)
-	return &PublicKeyMT{
-		H:    h,
-		D:    d,
-		Root: key[1:33],
-		Seed: key[33:65],
-	}, nil
+func (pub *PublicKeyMT) Verify(bsig, msg []byte) bool {
+	s := scratches.Get().(*scratch)
+	s.rd.Reset(msg)
+	ok, _ := pub.verifyReader(s, bsig, &s.rd)
+	s.rd.Reset(nil)
+	scratches.Put(s)
+	return ok
 }
 
-//VerifyMT verifies msg by XMSS^MT.
-func VerifyMT(bsig, msg, bpk []byte) bool {
-	pk, err := DeserializeMT(bpk)
-	if err != nil {
-		return false
+// VerifyReader is Verify for the message read from m until EOF. The message
+// is hashed while it is read, so it need not fit into memory. An error is
+// returned only if reading m fails; m is not read if bsig is malformed.
+func (pub *PublicKeyMT) VerifyReader(bsig []byte, m io.Reader) (bool, error) {
+	s := scratches.Get().(*scratch)
+	ok, err := pub.verifyReader(s, bsig, m)
+	scratches.Put(s)
+	return ok, err
+}
+
+//verifyReader is VerifyReader with the working memory s.
+func (pub *PublicKeyMT) verifyReader(s *scratch, bsig []byte, m io.Reader) (bool, error) {
+	if pub.validate() != nil {
+		return false, nil
 	}
-	sig, err := bytes2MTsig(bsig, pk.D, pk.H)
-	if err != nil {
-		return false
+	sig := s.xmssMTSig()
+	if sig.setBytes(bsig, &pub.XMSSMTParameters) != nil {
+		return false, nil
 	}
-	r := make([]byte, 32*3)
+	prf := s.prf(pub.hash(), pub.publicSeed)
+	n := int(pub.N)
+	r := s.r[:3*n]
 	copy(r, sig.r)
-	copy(r[32:], pk.Root)
-	binary.BigEndian.PutUint64(r[64+24:], sig.idx)
-	hmsg := hashMsg(r, msg)
-	prf := newPRF(pk.Seed)
-
-	mask := uint64((1 << (pk.H / pk.D)) - 1)
-	idxTree := sig.idx >> (pk.H / pk.D)
-	idxLeaf := uint32(sig.idx & mask)
-	node := rootFromSig(idxLeaf, hmsg, sig.sigs[0], prf, 0, idxTree)
+	copy(r[n:], pub.root)
+	copy(r[2*n:], zero64)
+	binary.BigEndian.PutUint64(r[3*n-8:], sig.index)
+	node, err := hashMsgReader(s, prf.hash, r, m)
+	if err != nil {
+		return false, err
+	}
 
-	for j := uint32(1); j < pk.D; j++ {
+	h := pub.treeHeight()
+	mask := uint64(1)<<h - 1
+	idxTree := sig.index
+	for j := uint32(0); j < pub.Layers; j++ {
 		idxLeaf := uint32(idxTree & mask)
-		idxTree = idxTree >> (pk.H / pk.D)
-		node = rootFromSig(idxLeaf, node, sig.sigs[j], prf, j, idxTree)
+		idxTree >>= h
+		node = rootFromSig(s, idxLeaf, node, sig.sigs[j], prf, j, idxTree, pub.concurrency())
+	}
+	return bytes.Equal(node, pub.root), nil
+}
+
+// SetConcurrency sets the number of goroutines which compute the WOTS+
+// chains when verifying like PublicKey.SetConcurrency.
+func (pub *PublicKeyMT) SetConcurrency(n int) {
+	if n < 0 {
+		n = 0
 	}
-	return bytes.Equal(pk.Root, node)
+	pub.procs = n
 }
 
-type privKeyMT struct {
-	Index  uint64
-	merkle []*merkle
-	H      uint32
-	D      uint32
+//concurrency returns the number of goroutines for the key.
+func (pub *PublicKeyMT) concurrency() int {
+	if pub.procs > 0 {
+		return pub.procs
+	}
+	return runtime.GOMAXPROCS(-1)
+}
+
+// MarshalBinary encodes the public key in the format of RFC 8391,
+// OID || root || SEED.
+func (pub *PublicKeyMT) MarshalBinary() ([]byte, error) {
+	if pub.OID == 0 {
+		return nil, fmt.Errorf("xmss: %s has no OID", pub.XMSSMTParameters)
+	}
+	n := int(pub.N)
+	b := make([]byte, pub.PublicKeySize())
+	binary.BigEndian.PutUint32(b, pub.OID)
+	copy(b[4:], pub.root)
+	copy(b[4+n:], pub.publicSeed)
+	return b, nil
 }
 
-func (p *PrivKeyMT) exports() *privKeyMT {
-	return &privKeyMT{
-		Index:  p.index,
-		merkle: p.merkle,
-		H:      p.h,
-		D:      p.d,
+// UnmarshalBinary decodes a public key in the format of RFC 8391.
+func (pub *PublicKeyMT) UnmarshalBinary(b []byte) error {
+	if len(b) < 4 {
+		return errors.New("xmss: invalid length of public key")
+	}
+	params, err := XMSSMTParametersByOID(binary.BigEndian.Uint32(b))
+	if err != nil {
+		return err
 	}
+	if err := params.validate(); err != nil {
+		return err
+	}
+	if len(b) != params.PublicKeySize() {
+		return errors.New("xmss: invalid length of public key")
+	}
+	n := int(params.N)
+	pub.XMSSMTParameters = *params
+	pub.root = append([]byte(nil), b[4:4+n]...)
+	pub.publicSeed = append([]byte(nil), b[4+n:]...)
+	return nil
 }
 
-func (p *PrivKeyMT) imports(s *privKeyMT) {
-	p.index = s.Index
-	p.merkle = s.merkle
-	p.h = s.H
-	p.d = s.D
+func (pub *PublicKeyMT) Export() *PublicKeyMTExport {
+	return &PublicKeyMTExport{
+		XMSSMTParameters: pub.XMSSMTParameters,
+		PublicSeed:       pub.publicSeed,
+		Root:             pub.root,
+	}
 }
 
-//MarshalJSON  marshals PrivKeyMT into valid JSON.
-func (p *PrivKeyMT) MarshalJSON() ([]byte, error) {
-	return json.Marshal(p.exports())
+func (pub *PublicKeyMT) Import(key *PublicKeyMTExport) {
+	pub.XMSSMTParameters = key.params()
+	pub.publicSeed = key.PublicSeed
+	pub.root = key.Root
 }
 
-//UnmarshalJSON  unmarshals JSON to PrivKeyMT.
-func (p *PrivKeyMT) UnmarshalJSON(b []byte) error {
-	var s privKeyMT
-	err := json.Unmarshal(b, &s)
-	if err == nil {
-		p.imports(&s)
+// params returns the parameters of an export. Exports that only carry
+// height and layers get the SHA2 parameters with n = 32.
+func (key *PublicKeyMTExport) params() XMSSMTParameters {
+	if key.N == 0 {
+		return defaultXMSSMTParameters(key.Height, key.Layers)
 	}
-	return err
+	return key.XMSSMTParameters
 }
 
-//EncodeMsgpack  marshals PrivKeyMT into valid msgpack.
-func (p *PrivKeyMT) EncodeMsgpack(enc *msgpack.Encoder) error {
-	return enc.Encode(p.exports())
+type xmssMTSig struct {
+	index uint64
+	r     []byte
+	sigs  []*xmssSigBody
 }
 
-//DecodeMsgpack  unmarshals msgpack to PrivKey.
-func (p *PrivKeyMT) DecodeMsgpack(dec *msgpack.Decoder) error {
-	var s privKeyMT
-	if err := dec.Decode(&s); err != nil {
-		return err
+//bytes encodes the signature with an index of ceil(h / 8) bytes.
+func (x *xmssMTSig) bytes() []byte {
+	n := len(x.r)
+	d := len(x.sigs)
+	h := len(x.sigs[0].auth)
+	idxSize := (h*d + 7) / 8
+	bytesPerLayer := (len(x.sigs[0].sig) + h) * n
+	sig := make([]byte, idxSize+n+bytesPerLayer*d)
+	putIndex(sig[:idxSize], x.index)
+	copy(sig[idxSize:], x.r)
+	for i, body := range x.sigs {
+		copy(sig[idxSize+n+bytesPerLayer*i:], body.bytes())
+	}
+	return sig
+}
+
+//putIndex writes idx to b in big endian.
+func putIndex(b []byte, idx uint64) {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i] = byte(idx)
+		idx >>= 8
+	}
+}
+
+//getIndex reads a big endian index from b.
+func getIndex(b []byte) uint64 {
+	var idx uint64
+	for _, v := range b {
+		idx = idx<<8 | uint64(v)
+	}
+	return idx
+}
+
+func bytes2MTsig(b []byte, params *XMSSMTParameters) (*xmssMTSig, error) {
+	sig := &xmssMTSig{}
+	if err := sig.setBytes(b, params); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+//setBytes sets x to the signature b, reusing the bodies of x.
+func (x *xmssMTSig) setBytes(b []byte, params *XMSSMTParameters) error {
+	n := int(params.N)
+	d, h := params.Layers, params.Height
+	idxSize := params.indexSize()
+	bytesPerLayer := (wlen(n) + int(h/d)) * n
+	if len(b) != params.SignatureSize() {
+		return errors.New("invalid length of bytes")
+	}
+	index := getIndex(b[:idxSize])
+	if h < 64 && index>>h != 0 {
+		return errors.New("index of signature is out of range")
+	}
+	x.index = index
+	x.r = b[idxSize : idxSize+n]
+	if len(x.sigs) != int(d) {
+		x.sigs = make([]*xmssSigBody, d)
+		for i := range x.sigs {
+			x.sigs[i] = &xmssSigBody{}
+		}
+	}
+	for i, body := range x.sigs {
+		start := idxSize + n + i*bytesPerLayer
+		body.setBytes(b[start:start+bytesPerLayer], n, int(h/d))
 	}
-	p.imports(&s)
 	return nil
 }
-*/
\ No newline at end of file
+
+// SignatureMT is a parsed XMSS^MT signature, index || r || one XMSS
+// signature body per layer, from the bottom layer upwards. The slices
+// returned by its accessors share memory with it.
+type SignatureMT struct {
+	XMSSMTParameters
+	sig *xmssMTSig
+}
+
+// ParseSignatureMT is the XMSS^MT counterpart of ParseSignature.
+func ParseSignatureMT(params *XMSSMTParameters, b []byte) (*SignatureMT, error) {
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+	sig, err := bytes2MTsig(append([]byte(nil), b...), params)
+	if err != nil {
+		return nil, fmt.Errorf("xmss: %s", err)
+	}
+	return &SignatureMT{
+		XMSSMTParameters: *params,
+		sig:              sig,
+	}, nil
+}
+
+// Index returns the index of the one-time key which made the signature.
+func (s *SignatureMT) Index() uint64 {
+	return s.sig.index
+}
+
+// R returns the randomness r of the message hash.
+func (s *SignatureMT) R() []byte {
+	return s.sig.r
+}
+
+// WOTS returns the chain values of the WOTS+ signature of the given layer,
+// where 0 is the bottom layer.
+func (s *SignatureMT) WOTS(layer int) [][]byte {
+	return s.sig.sigs[layer].sig
+}
+
+// AuthPath returns the nodes of the authentication path of the given layer,
+// from the leaf level upwards.
+func (s *SignatureMT) AuthPath(layer int) [][]byte {
+	return s.sig.sigs[layer].auth
+}
+
+// MarshalBinary encodes the signature in the format of RFC 8391.
+func (s *SignatureMT) MarshalBinary() ([]byte, error) {
+	return s.sig.bytes(), nil
+}
@@ -20,20 +20,32 @@
 
 package xmss
 
-/*
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"runtime"
+	"testing"
+
+	"github.com/AidosKuneen/numcpu"
+)
+
 func TestXMSSMT(t *testing.T) {
 	n := numcpu.NumCPU()
 	npref := runtime.GOMAXPROCS(n)
 	seed := generateSeed()
-	mer, err := NewPrivKeyMT(seed, 40, 4)
+	mer, pub, err := NewXMSSMTKeyPair(40, 4, seed)
 	if err != nil {
-		t.Error(err)
+		t.Fatal(err)
 	}
 	msg := []byte("This is a test for XMSS.")
 	var pre []byte
 	for i := 0; i < 100; i++ {
-		sig := mer.Sign(msg)
-		if !VerifyMT(sig, msg, mer.PublicKey()) {
+		sig, err := mer.Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !pub.Verify(sig, msg) {
 			t.Error("XMSS^MT sig is incorrect")
 		}
 		if pre != nil && bytes.Equal(pre, sig) {
@@ -43,8 +55,11 @@ func TestXMSSMT(t *testing.T) {
 	}
 	mer.index = 1<<32 + 223
 	for i := 0; i < 100; i++ {
-		sig := mer.Sign(msg)
-		if !VerifyMT(sig, msg, mer.PublicKey()) {
+		sig, err := mer.Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !pub.Verify(sig, msg) {
 			t.Error("XMSS^MT sig is incorrect")
 		}
 		if pre != nil && bytes.Equal(pre, sig) {
@@ -52,9 +67,179 @@ func TestXMSSMT(t *testing.T) {
 		}
 		pre = sig
 	}
+	if pub.Verify(pre, []byte("This is another message.")) {
+		t.Error("XMSS^MT sig must not verify another message")
+	}
 
 	runtime.GOMAXPROCS(npref)
 }
+
+func TestXMSSMTSignReader(t *testing.T) {
+	priv, pub, err := NewXMSSMTKeyPair(4, 2, generateSeed())
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("This is a test for XMSS^MT.")
+	errRead := errors.New("read error")
+	if _, err := priv.SignReader(&errReader{n: 10, err: errRead}); err != errRead {
+		t.Errorf("error %v of the reader is not returned", err)
+	}
+	if priv.index != 0 {
+		t.Errorf("index %d must not be used after a read error", priv.index)
+	}
+	sig, err := priv.SignReader(bytes.NewReader(msg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pub.Verify(sig, msg) {
+		t.Error("XMSS^MT sig of the reader is incorrect")
+	}
+	if ok, err := pub.VerifyReader(sig, bytes.NewReader(msg)); !ok || err != nil {
+		t.Errorf("verification of the reader is incorrect: %v", err)
+	}
+	if _, err := pub.VerifyReader(sig, &errReader{n: 10, err: errRead}); err != errRead {
+		t.Errorf("error %v of the reader is not returned", err)
+	}
+}
+
+func TestXMSSMTAllocs(t *testing.T) {
+	priv, pub, err := NewXMSSMTKeyPair(10, 2, generateSeed())
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv.SetConcurrency(1)
+	pub.SetConcurrency(1)
+	msg := []byte("This is a test for XMSS^MT.")
+	var sig []byte
+	//The signatures stay in the first tree of the bottom layer, which is
+	//not rebuilt.
+	allocs := testing.AllocsPerRun(20, func() {
+		if sig, err = priv.Sign(msg); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs > 1 {
+		t.Errorf("Sign allocates %v times, want only the signature", allocs)
+	}
+	allocs = testing.AllocsPerRun(20, func() {
+		if !pub.Verify(sig, msg) {
+			t.Error("XMSS^MT sig is incorrect")
+		}
+	})
+	if allocs != 0 && !raceEnabled {
+		t.Errorf("Verify allocates %v times", allocs)
+	}
+}
+
+func TestXMSSMTHeights(t *testing.T) {
+	params := []XMSSMTParameters{
+		{Height: 20, Layers: 4},
+		{Height: 40, Layers: 8},
+		{Height: 60, Layers: 12},
+	}
+	msg := []byte("This is a test for XMSS^MT.")
+	for _, p := range params {
+		mer, pub, err := NewXMSSMTKeyPair(p.Height, p.Layers, generateSeed())
+		if err != nil {
+			t.Fatal(err)
+		}
+		mer.index = 1<<(p.Height-1) + 12345
+		sig, err := mer.Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !pub.Verify(sig, msg) {
+			t.Errorf("XMSS^MT sig is incorrect (%d/%d)", p.Height, p.Layers)
+		}
+	}
+	if _, _, err := NewXMSSMTKeyPair(20, 3, generateSeed()); err == nil {
+		t.Error("height must be a multiple of layers")
+	}
+}
+
+func TestXMSSMTExhausted(t *testing.T) {
+	//the last one-time key of a hypertree of height 64 is never used.
+	params := []struct {
+		XMSSMTParameters
+		last uint64
+	}{
+		{XMSSMTParameters{Height: 4, Layers: 2}, 1<<4 - 1},
+		{XMSSMTParameters{Height: 64, Layers: 32}, 1<<64 - 2},
+	}
+	msg := []byte("This is a test for XMSS^MT.")
+	for _, p := range params {
+		mer, pub, err := NewXMSSMTKeyPair(p.Height, p.Layers, generateSeed())
+		if err != nil {
+			t.Fatal(err)
+		}
+		mer.index = p.last - 1
+		for i := 0; i < 2; i++ {
+			sig, err := mer.Sign(msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !pub.Verify(sig, msg) {
+				t.Errorf("XMSS^MT sig is incorrect (%d/%d)", p.Height, p.Layers)
+			}
+		}
+		index := mer.index
+		if _, err := mer.Sign(msg); err != ErrKeyExhausted {
+			t.Errorf("exhausted key must not sign (%d/%d): %v", p.Height, p.Layers, err)
+		}
+		if mer.index != index {
+			t.Errorf("index %d of the exhausted key is changed", mer.index)
+		}
+	}
+}
+
+func TestParseSignatureMT(t *testing.T) {
+	params, err := XMSSMTParametersByName("XMSSMT-SHA2_20/4_256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mer, _, err := NewXMSSMTKeyPairFromParams(params, generateSeed())
+	if err != nil {
+		t.Fatal(err)
+	}
+	mer.index = 1<<19 + 123
+	msg := []byte("This is a test for XMSS^MT.")
+	b, err := mer.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := ParseSignatureMT(params, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sig.Index() != 1<<19+123 {
+		t.Errorf("index %d of signature is incorrect", sig.Index())
+	}
+	if !bytes.Equal(sig.R(), b[3:3+params.N]) {
+		t.Error("r of signature is incorrect")
+	}
+	for j := 0; j < int(params.Layers); j++ {
+		if len(sig.WOTS(j)) != wlen(int(params.N)) {
+			t.Errorf("length %d of the WOTS+ signature of layer %d is incorrect", len(sig.WOTS(j)), j)
+		}
+		if len(sig.AuthPath(j)) != int(params.treeHeight()) {
+			t.Errorf("length %d of the auth path of layer %d is incorrect", len(sig.AuthPath(j)), j)
+		}
+	}
+	if !bytes.Equal(sig.WOTS(0)[0], b[3+params.N:3+2*params.N]) {
+		t.Error("WOTS+ signature of the bottom layer is incorrect")
+	}
+	if bb, err := sig.MarshalBinary(); err != nil || !bytes.Equal(bb, b) {
+		t.Errorf("marshaled signature is incorrect: %v", err)
+	}
+	if _, err := ParseSignatureMT(params, b[1:]); err == nil {
+		t.Error("signature of invalid length must not be parsed")
+	}
+	b[0] = 0xff
+	if _, err := ParseSignatureMT(params, b); err == nil {
+		t.Error("signature with an out of range index must not be parsed")
+	}
+}
+
 func TestXMSSMT2(t *testing.T) {
 	n := numcpu.NumCPU()
 	npref := runtime.GOMAXPROCS(n)
@@ -63,7 +248,7 @@ func TestXMSSMT2(t *testing.T) {
 	wotsSeedS := "7f392beb684110f1ee3f1ab105dd7c48bdaefc9440d276123995c98d3fe220a5"
 	msgSeedS := "660f4e90378019d8463a5466e18f8f787719a1898650ffa23796b13f8414f5c9"
 	pubkeyS := "eefabe301324f03808f2cde73c5e8b7a2673e2345d82b59f47eb1af91e92f162"
-	sigS := "0000000000000000" +
+	sigS := "0000000000" +
 		"512b1cac03116f10136e898baad56e20015a961eb93bf54" +
 		"e2c481ba9a5f7684a0b99de34dd62a40e902ab2b65669b0c160f3af3479c9c8d" +
 		"dccf09be4190c2af8ed1ae6619f2e5c5e54c85aad58a4a7f146105146dcfd920" +
@@ -375,7 +560,7 @@ func TestXMSSMT2(t *testing.T) {
 		"d29674ec4886eeaf5ecb8b5ebb7d3fc07783535bc274afcdaec12b2fdd781b85" +
 		"7ec5df1c1d38a0a53"
 
-	sig2S := "000000020000007b" +
+	sig2S := "020000007b" +
 		"223d06d23e1999f5505a412095ef9907771486a679f1151" +
 		"125ece1de56093ba275644d092d5b419ababf1175b3a5e92c10dae651369a46e" +
 		"ef0bcf12b02a0fee7b0ae192d9f015b5d91e5e5bfdb5af959aed6837f74588bc" +
@@ -711,94 +896,92 @@ func TestXMSSMT2(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	mer, err := NewPrivKeyMT([]byte{0}, 40, 4)
+	mer, pub, err := NewXMSSMTKeyPairWithParams(40, 4, wotsSeed, msgSeed, pubSeed)
 	if err != nil {
-		t.Error(err)
+		t.Fatal(err)
 	}
-	mer.merkle[3] = newMerkle(10, wotsSeed, msgSeed, pubSeed, 3, 0)
-	if !bytes.Equal(pubkey, mer.merkle[3].priv.root) {
+	if !bytes.Equal(pubkey, pub.root) {
 		t.Error("should be equal")
 	}
+	bpub, err := pub.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(bpub) != "00000004"+pubkeyS+pubSeedS {
+		t.Error("encoding of public key is incorrect", hex.EncodeToString(bpub))
+	}
+	pub2 := new(PublicKeyMT)
+	if err := pub2.UnmarshalBinary(bpub); err != nil {
+		t.Fatal(err)
+	}
 	msg := []byte("This is a test for XMSS.")
 	msg = append(msg, 0x0a)
-	sig := mer.Sign(msg)
+	sig, err := mer.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
 	if !bytes.Equal(sig, csig) {
 		t.Error("should be equal", hex.EncodeToString(sig))
 		t.Error(hex.EncodeToString(csig))
 	}
-	for i := range sig {
-		if sig[i] != csig[i] {
-			t.Log(i)
-		}
-	}
-	if !VerifyMT(sig, msg, mer.PublicKey()) {
+	if !pub2.Verify(sig, msg) {
 		t.Error("XMSS^MT sig is incorrect")
 	}
 	mer.index = 1<<33 + 123
-	sig = mer.Sign(msg)
+	sig, err = mer.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
 	if !bytes.Equal(sig, csig2) {
 		t.Error("should be equal", hex.EncodeToString(sig))
 	}
-	if !VerifyMT(sig, msg, mer.PublicKey()) {
+	if !pub.Verify(sig, msg) {
 		t.Error("XMSS^MT sig is incorrect")
 	}
 	runtime.GOMAXPROCS(npref)
-
 }
 
-func TestXMSSMTMarshal(t *testing.T) {
+func TestXMSSMTExportImport(t *testing.T) {
 	n := numcpu.NumCPU()
 	npref := runtime.GOMAXPROCS(n)
 	seed := generateSeed()
-	mer, err := NewPrivKeyMT(seed, 40, 4)
+	mer, pub, err := NewXMSSMTKeyPair(20, 2, seed)
 	if err != nil {
 		t.Fatal(err)
 	}
 	msg := []byte("This is a test for XMSS^MT.")
-	dat, err := json.Marshal(mer)
+	mer.index = 1030
+	sig, err := mer.Sign(msg)
 	if err != nil {
-		t.Error(err)
-	}
-	mer2 := PrivKeyMT{}
-	if err = json.Unmarshal(dat, &mer2); err != nil {
-		t.Error(err)
+		t.Fatal(err)
 	}
-	sig := mer.Sign(msg)
-	sig2 := mer2.Sign(msg)
-	if !bytes.Equal(sig, sig2) {
-		t.Error("invlaid json marshal")
+	if !pub.Verify(sig, msg) {
+		t.Error("XMSS^MT sig is incorrect")
 	}
 
-	mdat, err := msgpack.Marshal(mer)
-	if err != nil {
-		t.Error(err)
-	}
-	mmer := PrivKeyMT{}
-	if err = msgpack.Unmarshal(mdat, &mmer); err != nil {
-		t.Error(err)
+	mer2 := new(PrivateKeyMT)
+	if err := mer2.Import(mer.Export()); err != nil {
+		t.Fatal(err)
 	}
-	sig = mer.Sign(msg)
-	msig := mmer.Sign(msg)
-	if !bytes.Equal(sig, msig) {
-		t.Error("invlaid msgpack marshal")
+	pub2 := new(PublicKeyMT)
+	pub2.Import(pub.Export())
+	if mer2.index != 1031 {
+		t.Errorf("index is not 1031: %d", mer2.index)
 	}
-
-	var buf bytes.Buffer
-	enc := msgpack.NewEncoder(&buf).StructAsArray(true)
-	if err = enc.Encode(mer); err != nil {
+	sig, err = mer.Sign(msg)
+	if err != nil {
 		t.Fatal(err)
 	}
-	dec := msgpack.NewDecoder(&buf)
-	mmer2 := PrivKeyMT{}
-	if err := dec.Decode(&mmer2); err != nil {
+	sig2, err := mer2.Sign(msg)
+	if err != nil {
 		t.Fatal(err)
 	}
-	sig = mer.Sign(msg)
-	msig2 := mmer2.Sign(msg)
-	if !bytes.Equal(sig, msig2) {
-		t.Error("invlaid msgpack marshal")
+	if !bytes.Equal(sig, sig2) {
+		t.Error("invalid export/import")
+	}
+	if !pub2.Verify(sig2, msg) {
+		t.Error("XMSS^MT verification after import is incorrect")
 	}
 
 	runtime.GOMAXPROCS(npref)
 }
-*/
\ No newline at end of file
@@ -22,7 +22,11 @@ package xmss
 
 import (
 	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"math"
 	"runtime"
 	"strings"
@@ -31,10 +35,26 @@ import (
 	"github.com/AidosKuneen/numcpu"
 )
 
+//mustSign signs msg with priv and stops the test if it fails.
+func mustSign(tb testing.TB, priv *PrivateKey, msg []byte) []byte {
+	sig, err := priv.Sign(nil, msg, nil)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return sig
+}
+
 func TestXMSS(t *testing.T) {
 	n := numcpu.NumCPU()
 	npref := runtime.GOMAXPROCS(n)
 	t.Log(n)
+	testXMSSVectors(t)
+	runtime.GOMAXPROCS(npref)
+}
+
+//testXMSSVectors signs and verifies the known-answer vectors of
+//XMSS-SHA2_10_256.
+func testXMSSVectors(t *testing.T) {
 	skseed, err := hex.DecodeString("b041bf7ca73cc7905aadc1b6460da2e50206652e3d57a61487beb09664da308d")
 	if err != nil {
 		t.Fatal(err)
@@ -47,7 +67,10 @@ func TestXMSS(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	sk, pk := NewXMSSKeyPairWithParams(10, skseed, skprf, pubseed, 0, 0)
+	sk, pk, err := NewXMSSKeyPairWithParams(10, skseed, skprf, pubseed, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
 	if hex.EncodeToString(sk.root) != "a959a891573da8633b89e8f21e43eef9fca43a14bd2d71b1cf9ad5706945e752" {
 		t.Error("root of xmss is incorrect")
 		t.Log(hex.EncodeToString(sk.root))
@@ -58,7 +81,7 @@ func TestXMSS(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	sig := sk.Sign(msg)
+	sig := mustSign(t, sk, msg)
 	csig, err := hex.DecodeString("00000000" +
 		"2e988488989c3c60d881dcf702f69dd321af41865b7acdad535251f0681b1d1a24c3159f7c17e80639a6bd13a8197cf14591409cb9df1be4148f324a4db132e5d1fd634a15c2cb42e2dff30da0f05f9b21df8a162d8f01b081683a4220705da2c06713bc7a4a05b5d21c586bd1e4bfa453f73d58e4e2af41e07b799cfa7c7009a1c83ef26d1dcad14ec3e069278da0d2dce62838bb5b420b810e376a5825ba4ec7bff63ac3f7895c937fccaf42fb54e071e431353eca289bfa4d00be5fa457b1347f3166e86ffe5c39b1a8776826e3018272fad8b64c1a7ccf14ffad48abe932b1cf440f8a27e9fba5318243d3ad4cc30c9f885f0cb6671364348ce9b730a67bda011433e4b036537dbb50c7cb47fa52f86d041cbe381542de51f6e25950806c91e036bf48c4920d2830c5edfa69b30433ee6cb177e3598af3c0e40e1e401684df30c800fe1803c7bb2b4537fc7ba23a6cf709c28b0387f3cd7a2e506b74c42ce1d341ea4040a80303373006d7a6613030ff4133d965ae1ae8a127c81b0702ccc1b65c282bae25f86f06657d4d7ec962b7a3d380a7b338c73b30c0f5794c2994492cdeab0a89f6c0365a64a2a15ae47b552e9c1c70cf55d1ee7062b92772e36ed15b4444d1b279a1533f4237b1cec4604bc47b3a88f8ef4decd845c9ebcdeae48a09ef0fb0675980cfcf3d8d32fe24fceab08948889052f1ea474797c9f82430dff0d663b7f901a6490d1bfeccfbe78ad23dd6aa4ab2ff1c6b06320a5a746a643aafd97824e748e08f1f5152473e5dc36cc147fb251cd9fb8e4de5ce54069a268ea1e214d61b63e78542bfdc6429d2bfd57612ae374d09972f28fd16f6f72ed17c44cafd18322ed925a36fb6149f082d87c4de52b78ca60cfdb5073fc85f3a392d11013394e45142749d085460091235d9f26ef9bc8ccc8b7e66207ceca74389873e42e136560620cf32d5c97b3c0d83461f9dfbd1a97a420e1b6b24be288e14697d064e4c6335db23fccec382a94ee47541901871ed64d4e5cec21f345b82f48badd501a57b07050a65fda65f4964a901561c882b86513adfb3c31059d59fbaf5e87e924516956b43c6801d43ade723c0517a594a35c952f33e6150d35f6cc491d49517e28567d1fec2318c11390669bc7d20700717584fb96887288f5817d1ebdcdbd53d36f2f15b4b19b456c8e6c6f7a318e147b5eb01464f9a6030889fc59bcc41febc33a19de44306d5555fc245aa6c776f48a4a5a95154f24448f485e63294eb1b257f758404008a74a8a1732a9c10218a19e492c989137462917e012332ca4bbde12abccfd24b424055d2bd54ccd990753c9f375890d60a5c05fd71597c720276e32aae1874d4dae4639c916f018e49fec6377105f570a72c2b6110cb79a006eaa8ba0853f85c12acb29cc5a1a51772f1e7ba37684d27044f223b7b18c260739a4629fb65e87baed00b3cde3cb783d29f0df8ec8d73de980b791a27b1013341df742a01f99ae13fcbb663f4a2fb46a7b69e11a94fc839c198f1b9a4ed0059c995e2dc83eca3333f11dddb4d3cff916ef9ca57569a255941c05d111af3c103e14f8d9c831f17d2d43b39a5f42b9d0bc96ca039b1e49d4c6f47e61b480188cea5664e61596de0d0a87be61a1317018275ea3e2077649cf85e5c6d6a3f0c253014c06f3fd654e6f50ce99b53671d12b0024901bd9286e3f767a345b0cb798b71ee1ad24710beb58663e5239ded5d54531b4fd1a36b885a6b8d9b9a83fced90d1be5639ddbc9893ad3e89f722d6ddc1d4cd0ba7b699854369d1d866205db5de882d4e5a1622c7c568412521bb1efb1bf615215842b03634050b1fde31688db05c84b05a4eed3c9681d86df3164a4d274d5fdf385a18bc7a1c368cbb3e5b7b6558bd66cb04fc8a77f3223aa0640de5fcfbacf60af105971c4c4d9d3566dccac843b28ec3ab22d630e245ee12943fe2f1452179817625b5926d232979760a421d9acce4a0d9bd6929faa388953efbe82eb4de3b8e5b96c0093ff4d63a42a61490fd3df24830f24af575e38acef3cf5bc6ea8e85819dcb6ea0b269ea85f763f3aa9e30d5fc621ec1888b19230771af141208b33d74227dd3a33df5279a6ca38f96019e9fc6f4075a9a83502caeabfe6290424dfe7f0f881724cf238aa83cbf5eac151cca12533d6ec5fc487316c3c290637007382e85f0ed06fb186ba85b5ec448e635dd12ee47e683208627045e4d2f7bd9115120460a64b546e7a1a2012129461f7c14160c0351951aa25a305a4f76824503d6f3a7c44dc8179f76d16e9573503666a242ebd56d10e5e723d715cd5da0136e7fca8c676b6b521ca49ca64cbb289424da18678c935bc7578b43a0bab52e3137ab800b5aaac4954e27e8498c105abe52c612d5672326d3aa2030c432c026f2202480c9bbe37aa8db7d7af81b09093194aec1ffa30d3e53b673c0cb96c98599effe661b743a840c03b0910b37d1751c30695e2b7e9c37df2fe334da8a224fa6108d1225edb9d4169e44dea5e30cb5f6f5a2657a26fa19f039a94e20e37f31f58a327d2e61348f7ff6a43e0eb128ee524d7ae37ca23c9ec95953be56481dfe734d652a00337ecdc9d9832c1b048fc8a6d6f0c026551dbb182a41022cc8218c6946454c35c37d8e4a49a8c565d01910767a1d1a27fcfbca1b4dd6348d2dc5b2a249b2c30042e2d6312b5c1be437e6962d9d36cd71dceffd7cdf87df9f49f3f4d6ef89992ce9f07e6c116b71a0a1036e72f9069f8e08bc2025ef5e45c485ccfa135acc1d4841bc57e4116ae43d9058ecc851a6af84ccc6764de587176dd159a42a032afd2b392f0ab97ef674fa2e2c4eb6f00685e80686584fd94dd76d48a40a619508cfd92fbb8fbf3aa395c588afd6eebf6444ae9b956c29ea6be1768811c8fa02c1dc0b5034c298e8dc8c1e0b00e554483d3112440af818745f163d529dc26dacc54db1ceda5562986687245d872add971077dbdb405f5dc2abe51de713e6c9f05c9ea8b093dd6188a114632ed021d704488751d4b22f66b59aabfcadd11c30339a13049cb156f4581073486bad0965aeeef7349c81cc449d481c52c20c122db190d04f4432146e7c64241b43284a4d06e6213d71075dcec463639eb7beb89dbecafc95e4d8c7e4689f4054e41978014e2577c509ba16705ed109cbeb2356bff4631672364d70b5b9c5dfe41b2a6ae6fba61e35ee83ee377185cf735e10e493ca2ce642d789993d2aa20f876bbb837f3b9b5e3df8da433fa5683749efd7f4d00217335945f62b03a62ae2071f893a8c2726c35b83f5f181aff67b0365b639f1375bab04803db5d7969033ff85bdc3103afc07c90a4d3fd36e5ea9b4ff43481f45ed60ca7fbf4aa6586e99e5bb00f30f9f1f096a8bff6710c101324cbbe33511fd3ae25f7262774122a22986e51a76619e71563be23d4f93dbb604c3c2a267d933d9fb91675fe6f7c46adac7e053f921e441256a1fd568a5ce8975030472f916f7fd60da82c7eb57636145baece46bd1b1ae8425fc98d33b804431f0085570ab4e5ead7bf1625eaa302efe9020325f0d2f5a9d49b37cb8e150b385cc4699081bf5c292398f5e11072689fb74b9951a9880f4192adc81e318d2516cca806a6a54d987ed1e482151937076a0985cb56bf86e8b91289c53df0faef9ece0f507387b0dad2a0689b0e40445eb7fe4ff65a9f559d082a4211908c4b271037abe3aae3e4f4bd0607598a795c3b10ebc6f5698ea3b73fdf696656c252267b28b9300ddbf5e985ca2814fafdf2ec19d4e8f4f1c78f6a3bc872e526fdd558104afa78e485383b89840cbcc2671fcad714ace703a0241b994d00c48723ec60f679736fe895c2a4155e15baa9570143430f56e4fc2e3a7757cd4e62a89347c7a72554b7f5421680e338e682659e4d85c8c8411cf01ee3536cfd18dd8f7084f3d3cf9498bb07043fb723b417b1fafb6b8014830a8764a6ff835f34b519c423b25d17dc5e5283f39acaada60d3b1f3e940bbfb9ba874447b40dca0bcee77b16f2b3b38ac70cd1bfe8419b7fdfbad6369a3a0a09b85010ae93557ccc0272445f0eab52acbee26eb8bbcb356a252d46626f934b58fb8bbf81d5d2a0e3bbdd378ddab70d4926a488f062e93322f316c6399d2773b536a91baa1a010f4c8df2b772ab63689a484fc8d22eb994f0b2588d995d6ad3c11f791b37d90f6bbbefe7df6d39b177ab36142ec5ae29b8817c0e1d37ab3f18a694f105b25516ea2f1a75bf8df17e930492eb6d849a3519f26cf1f3c50dd5614b5dd663c14acb45ccb25b29eb3247982a678f1fd79308897074af11a2ae54d5d964e5dcacd9e4476586b44365c633e2939297af999277983ac3edf1ce23c785587b24b30a3fa5223ccacc5f91f32b9540f967f92fcac9c88a84a055a873abd2273aa23dcd7a800e1225aa867218eb626839d8dd8c8b6e8b3ba81d98197ec66d28860d0bea9fb676966e444ff255955840746a84e517b340314a6c911024ecfd3501bcd2f86821d9a3db159dd200f16e10bafdf7bbd885c9383948fce204d5a7b34d3ff2973c8fac2504b76d1939daa930cd7c3ce3592bbc83f8c98f964aaea59493dfc00e95765724369e16357cb90ff2e9467350d4212a80e43272e481e57c16a5f907e8e0e4a577a793c5747c7068edfe23858c38523faaaeddd29af3236761771dde186e9f46cae665fedbd1b2406ddc3e108e6f0349ab952cadb65cffa3e2cd6e6a0d9488d66e3a2fcb2f02d47cf3fb86fd40f744e4f7f46984b47f096f865491580cab66cd884f9663165f5b55e3eeb9248bf30febc7e494ff6c3a931bdae60bf9f8c8622e2a04359adbf28444d3e444bcbb9699a3b3051481983436407af6396f744864fb3a5cd672fe667274964298cdc550e310040d83a57e2fd4aad3c66b10f29c0f2c015b34be2588c949026db6ede09e543ef397d3aa1ac4243387fc80ca79a797e3b4abd49b65e12ac91837c115b118c23420c846b525cf13aa300257d2503733ef1788b1cdaafdcb738433e807c47e273c717f4f3e631325ff95b800c9b081e9d737f640a85b925cb565f268ff85af83ad2d2d7395c7892908760d06ce8d37e07dedfd83ba11c45b50ce08470400170a2aaf2985c9336863fc663d2eec39a7594e0e40c054abefb7ec0051ba0c13460d1f3dc7797e80e02ee41adcc61aae7c709686cb38d47b3bd16973e2312d93c47c7612127ee04a931e0736f616a7faf80702cf472b5756c7bdd1bc3ca7f4d9fe3d848b281d7eeb50ae6fad44210205d9a0ed37925eead032e0099999416f73b6b9142ac5049a003b364c39cc51c2fb1bb6704a297aa3265869615ab9cc7b218fe9671aef3f5d1940ac339bc7f45063bb4b42084eff3e0dbda8efda6ca6c5ddfded33712500cfd3b80c5776f5c52e5a0875a2a5689c91478585e63e57319d6c5e4228a38e95c0cb4892822ad17139db6e1380803d49cd6b5f964c2e48471002244c66be1893235d5a8db1835cf45d0e74663ad4eb6f70f7917f06d9e7a43b0f11aeb45dcdb8ad7b9d40d73b1b81fb7956c02bfabd4632c7da726b20b1e43ed99814731d9009b3f66a4de89255a37bd29fe3bfd0333ac94533579fa34c5871b5958bae1d0e76404496991b4f10667f7d19df47f4d34d279a2e257440e45b46159533990cc0163834396fffdd249e9686fe12a05cb690bb324207731a182a3d869f7c287a4709f78050b25a9485f48b412f13064640e914162e3b679131994bc358f2844801c75e8654c2e63136a6b87605fec6af1320f19f36b775cc2193c9fd4c9d2fdcf6792f9ffa9b486d944cfcc1b19ca253d6cd9b245b66cc122efdf66734193715485a55456c785fb86bbebfe8ecaae126a96ba4c799c88b9aece91a9e64e25b67b14175f7a7cfdf6a08bd8fdd1da392a3fee794c856dff760d0bdbc3e364b80751cc4e9b789c8a8e3ebe5e59dd315cae30a43025a87d336211f05dda1db18f90f789025fdfc2af5cf70e230f7133bfae60036e028d628aae40e98f3247b3eff4d4603ca2422424676af215c765a7e883e818512736899a96351d3bdd0be8dae10f8d2db0e082f0b54340c3ca2e6b5e55341e2eda64c8629f787396bbba9d262477af2f4a3558f80d05c0070feb557d15556623b49be2d88ef0d665fca9306cac5ad4e575915502dedca1d5fe0c025c980e834a2ea3f8a61d371cac7b038b6a7234df6b17d494860dd55326db10dad79f00153895d672ffd4806fd5a5bdf53f57aedd75e27a0f8693d49bfb447fd549800a533eed550b8de4df9f419210cfc6e1c41a7b2ec0a698b5372640684e5c8a341e9de65395de82461550ddf6151cd9a46b9b8934c31d28b2a2670c64e91e864e7f20ee2a65b8d802fa38839ed863949dc127d65873cbaaa8bb4049b37d382c20988bc5485a8099385385db4b28a5d7066c6655b9725f7d395454f248957f86d0807ef09fad016db752c17714886f27a6ea392990d01bb782f88015bdd9515193d7afa641e6157893d6ab165c64e4dafb7c9ad4a0ddf2dd77f73d85ba9f55b26464a2e8b42050c4198dc282297a5d2bf46cd37c0d4916861cb9ce2ea7379d786e570494597918bc730f0fed9ae6eb4f374fae002f682085196454993945a74c58f3eb92ad853ea41cb06808bf6919df58cee3478e4924e3fa753261a0c421d1512f18d52691622b354ce6ca846c80ecd433df7708eccb71176915d6c590682403f01bd8cbfae1740c829bea7ce053718c0b204f73a976ab107fbd4779ed8c4ea1faa868fb695ca05e922df5b9a29bb81e5c12758d4a14985f1c9403efec23651a047bac20bb755c0fa3ec2446601c1e8711be0e681c7bfd0df654b4fabcb47bb9787a7b17416bdce001ae1694a00af222c9dfdc33fc9ac0aab9d9ef3d5b7bf4ba8a494f937d9c873a2e748fd087d7f383c7b873814a20fbaf7a37b2c37e308af6c1c1588a4523a0671594a99ccca5a6dc2aa321e0b58ba390f899206621a7781285cbe2d9aea7ed7756219f607348c415b00d34a75ab22053ad1756beb69af1216d05f1f132baf666613f4fa149f7f51611c6addeff350add6555e1dc9a049b2a5166baa5599daffc8a9274d4125578589f4d9182e6b4bcc37f6bb3bc707d8451d330c812d4f42acc2fd63a80b77f2acd86469be7fbaa34674f978be1d6a5dddfd1df49d97968003b13bf3a9d322bc5b01457ee08126ccde914de442300bcabfbfff5fec22aa9fad6d3357d363381222f30aef45d3515f3b60a6ab3d664b634507ec8c3865a47eced90203ce9a39b2a3671c8033b201840da18436c7d3c89b6c602e29a05c0f55e2ef4bca2746f1af04c0707cdf7f2e35aa33ac8a00f46d3672ce8ddba4af904117af2fc40f989ee591e6baa412dbec666c466f75ab9f5a61424444061f3bf53de83943b8e7cbfccec79352eb35512a6fa6622f978715c49fb11f9cedc931918ab51185c98a4c66d2d37da16a11b28a8fec727710d7f70dea5be4ef84609319c01ab1d88148ed8fb79c4886e18279b2b77dee20dd7a5d4b3d9aefe04f36aff01196601fd5664372ef0e8796e7ddf4a8f0952c3dd30d2280aba2b766e5a3d2b846c1891900f6f46ca023e2f0cc08e10cf69de2bc5d48edf427741f21914f905955726a8d9c6811299ed568e96ea5fd35ded053ddfbca4b2a801d1cf651fe865bba7045abe8c5ab0e2aa4808629c291842390d8dc94dea845c477aa2a1dd0a3fa442a6d2262f0f607ba52b8a63a26814e0193d89c5d0a02d667bd2b0d1733bf1cf189b6d179b30970dbb59db5523f3cf672f1ed49e7f1f2a38440e98064950cca61bfe6fe3a24e1c1278828fc7b8accdccc926876dc875229ae06f320e07f0e3fadbd1ca967495be3e36a44767efa0e7a862a1bfbbf935812fd67093850e3d0fca0efa550c777195280e9a2497d4faf80999a9add95aed27487999a377b0f978c06a6c2ff13ebe88230b7adef386edd3d93e38ee297249777e8b23fa4cf3e462e918c4311b9dd51f7c5cb6364878fde4099708e7f4a3a5fe85209097ea77f6985d0415434266a2208029551c07b85a9433730c4515ed93a5f7545bc58e107206dedcd19a3ce7dfdd462bc786d4cfd4b2841c5d0d9f066261d648a7863fbe08803fb53b6e5b21f89b4150d14bab64ca23dfbb97070e20480c28aa1e2a20962db6a3350072edcc452c165d1bdf36e21267ca7a457426fde5530f903cf0bb02a23748e759ccc4475d9871b31a4f2267081db2e8ae2f5c8c1ae2d06642611b63c722cafab07255df4aabad93ad840cd5f1e2bf52bb387853a19f12c21fae20e24e01b73c3051da25767f08eb9543b840a81e3041cf6d356d7491545c0b3d90c9aeba3a71b0c2de6d6a333f782c122dc89ea2609be7da082224e2b6c7deb671a18c84c2716e19138658739f32a577e9a73e58a7e8f9544190635b")
 	if err != nil {
@@ -68,11 +91,11 @@ func TestXMSS(t *testing.T) {
 	if !bytes.Equal(sig, csig) {
 		t.Error(hex.EncodeToString(sig))
 		t.Error("XMSS sig is incorrect")
-		csigstr, err2 := bytes2sig(csig, 10)
+		csigstr, err2 := bytes2sig(csig, &pk.XMSSParameters)
 		if err2 != nil {
 			t.Error(err2)
 		}
-		sigstr, err2 := bytes2sig(sig, 10)
+		sigstr, err2 := bytes2sig(sig, &pk.XMSSParameters)
 		if err2 != nil {
 			t.Error(err2)
 		}
@@ -103,7 +126,7 @@ func TestXMSS(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	sig2 := sk.Sign(msg2)
+	sig2 := mustSign(t, sk, msg2)
 	csig2, err := hex.DecodeString("00000001" +
 		"44E714665584FDF0AB64A9F80767EEB54E598BF791C023899086F1737F7DC27522788EBB3DEBD2D7F9FE8BD05633663DFA189C5C8F5F65BD88B4D7C1FB694F78CE45821AC50B273B27B2F58FFD974ACE4C64991552D3F014CFB3A88BB027230B8B8739DAEDC5F6522616D7D4DBAD879E230FBA9CC3376161A5F62C0375EBD72CBF57268D01B81D4023226175455F0EDAC218426E89BD93D92D70E07061708372A54C0C8AD06A428B5B64F89735043F344EDBAC769F068762B50BFB6838312E3C85F718BFDB6550049188F9E21D71221E58B06AA54544DA497C7AD80B0E4D596F64BDA79EE804587F8977D2F97653379DC67DBFBC95871BFE881373ADFA6BB2FCC840E0CFCC036F55D13CAE45E9A948386305D0B349EDE3BC8B2454AD0B7A2DEBADC0977FB62AF5BFA9E4D97172AF89C4AE02B8D4D08EA227E289838125DA8C0DF4E16DF8EF12251637E3083965E02E37BD53A7E77CCE380E615A2A9E98FC5222C832E987C238D313681108110EA9C74AD727558CDD91235B6B98E78CC6F171A1AE6B4528D2BE67A53265BB8D544463F868D6FEA0C932497FFBF197DB189D961929296A7769E98AA82F015BFB9A85A6B2677C77F39A088D3DA1A3098CB7A5CA57A088583150C51D019475152B60A224EB84F4EE002E9941DECFA7E5660D69774FEC5CA1D2AFB6BF209FEE7B24F37D4C376524EF82C227C5C8F04B6820B0FED8450EB1DD531A7D57AC600DCF1D611882AF6CDB37BF3E1D84E252C22FE24AF3E3DC1637B4338C02443AF7347DFA288F17D7706100582C6E13D0B1F05AC6272AABA6F884C17C4E508FB47F0A3FD462214CE0EED746A007A3CF317BBB13389E9860E5FAAFEDE19B8B71CE78D00F394A618E841EFB37DB4B95B2AB34B73AD8401E14B9AA9A3F685650A698C61E12986D5DCE4893A29545BBEADFC543E1CE685994AA0C94FF7A04CAC3BDAE4C75773000280BE5A7620975781208537E781DE8ADACE79139247692DF9B849C719268E661EDB39ACD4AC399806C54EDCCFF612C1EBD57109B3B6BF073FFE7E56C148127A07A985E5AA92F1A2145916B92F89BA341F05EEF545EA20BEE274420FE1044E1B2BE178B8F8D3FDBA4E47167251D1E9128C9B54DC3B698257C38E488FB3F50B14F438658D4BCE73350FE534A7FC9958336BC2A2AA0FB84FFEFFC2E02DA72A97D1C6C0022602D01C1162C2E8221AB46FE3215ABF08D8CD506BDAA10B8AB579B6011C8201AA7ECE4246DD4954E09E843EA9B3B362BBD67D620636AC5265A8691D62CECBE022582955C8327F0D7DD42AC83BE0B2BE164060B640D653B663693E395BCD41D55C7A71A2797C2A166CBA9D6117BF40B88A497150B95F839789E35DA318EA7FC97E82A53B3BC53B1A8BC0570CA26509F955FD51C0228B4BB5AEA19D6E0BC7DF94D39FA9DC0DA50E32E73A6D95053561D58B47DA2190DCD4676FCE0E6613E59F1682EF9511C715F22D2C2E7BA29B6B614B0CC05A521BF0AD4F8C0DC0B62904D8B8F88374CD2D7A146D57110CBDED40EFBDF192A78B4B767D3A474D97F4C73B0BABAAE2F4F780F24C83A95447EB9493AE3578F675222BBD3B547E53DCCE1AA6FA5A3EF2A11F6C16DF53D5F7E8FDA216150B9E11E468536B1A3B2307312B969B7A9C446C62C8015870527F16CFFBCF5ED30CA0474AD0E82DACC8FBD3133C052B8253C85D271D438E59D00415350BAFAF406A20DD8658718DBFE3F9EAB2CB3DF0FF122689E63C7FB5E8D11ACCA10CB3CCABFD7699C003B5AEF7CC083A6EB3CF4E70CD5AFA1B9C430E2F89459B9839AD063E9C19CC3EFBB3FA4AF88C50080E48F3F9E61CB815FE0DC08D2B162C8C87A2F66ADB19267BF4D92AA3774BD0E7206CDDB1FAFFCBF346AAF9CA51B8183E7B051B30A5A71434649521866E903ACCB7C01F2CA5813D5EB79B896322D8CA252EDE317F3F7731A5931C829AC404492D1FA44436087181D325F4655B5D1B0ABEC2198AEC80BC448D9FC571BEF83A47615A85D5B9D70FFD536B91B0C794793E64B4F678F7BCA706ECE1240760799B0DDE1E994E40AE4AC4B6C1C8D3F741124258DE879838C4C08FD0DFEDD59E036D52885868B2EA5335E24A3AB3DCDE14539ABD739991C1D1D88B15CD67D98A93F65865390EA0001423DB2F2E2BDE8DF698EF8E8DAA5BAB674A6A551CAD9E5B110408D1191A0157741A3FB4A6BA36A717E3C58785246C8AF1B28C14AFCE042EFB9BAC4AB3E7D7238A44A7CE30A1C73F647E53FD4FCFC39D94630D7400E2A66E2526D31D9970EB2036DFDD791AAE41F0836D7FF20B4AFBD9AAA0379530386523BC4612E87927B356D0337703890B41F16DB5A1120C8ABDA6C9ED27E1628322E059C80AEA8FECF41F48DBBA19B27EA113082C09566885568568F5C2E12E1149B2A4EFC3CEECC02AF7F1F2050C406F4ECC53881186AB01F36DF7F386207E9428952F0D1943F38567AC127C6B26296F0C74DDB88A147FFC8DD20C4C4E76D0FD39A4488EE442833CD56A0DFC666BF74261457B0042CC7E0688168D1768DD38C98E6B601E58C970E7CF7B3A19AFCAE7392CD3A4216038F4666310407664EBD869C671D3432C760547BD58920B34F7FDCD881810F566058D1B4ACAFF28650D805DC3E24EAB4572E4E8771090C6945167F63AD60DFE8FD4306E961F0BF0728EE6CF1B919E484440A5ADDE9250833137AFF682C946141797E6C9D1ACF83216F5B3B7AD04B9A0C59BEDCD0A97D3962417F29697783EEACC4979525F854F89E24551796ACD45F56A584A9EA6652A14DBD20117C40AC0A41A0D3919AA31202ABF63AE3A6A40E868DFAE361778B9E3EA9844C020C22742BD503A6EC01546CA1B918424C0938F25A79E3A14864707CB3EC70ECCB104B247EE0668FD0D119EE93CDA0AEB46D930BADF3FDBFC4982ECD01FF45689B030AE92FC18E5C788034E3C4F273EEEF07E654651C510F0AD8C8E29D9E9F8553E1A6C26891DA4BDB2F2A7220C966BFDEAF4A91DD7341F8DFBBEB1F07D2089E35A6E528854931BE08E776B87B9C3EF04D357B524349CCB3C5EDD92CE3771D6A8BF5DA23DA19579DB80CE6893460E3B43AB0DFCA605B1BAF147CC5C2B7DCF017FF8CA8AD7EBD1FA20FB079EAA34A4D06E6213D71075DCEC463639EB7BEB89DBECAFC95E4D8C7E4689F4054E41978014E2577C509BA16705ED109CBEB2356BFF4631672364D70B5B9C5DFE41B2A6AE6FBA61E35EE83EE377185CF735E10E493CA2CE642D789993D2AA20F876BBB837F3B9B5E3DF8DA433FA5683749EFD7F4D00217335945F62B03A62AE2071F893A8C2726C35B83F5F181AFF67B0365B639F1375BAB04803DB5D7969033FF85BDC3103AFC07C90A4D3FD36E5EA9B4FF43481F45ED60CA7FBF4AA6586E99E5BB00F30F9F1F096A8BFF6710C101324CBBE33511FD3AE25F7262774122A22986E51A76619E71563BE23D4F93DBB604C3C2A267D933D9FB91675FE6F7C46ADAC7E053F921E441256A1FD568A5CE8975030472F916F7FD60DA82C7EB57636145BAECE41CF6A9842F3108C2F5F18064F0879997531B4DCF2D7747DD5F94CEBA2E3F6882ED42BE6CEA55F854D0A86712F7073A5BE0CFE23D9AE0A7E0A3AD60EDDAE32CA239A176C1A7C078673E957020B3A0CD1B8ED3271BC280E0EFA5BB1F58FF52E8E64479A0A684748F61794B58851BC8254FDB34E7513409EEFB401875A4B7CB043C09606F06F5D6B86ED258A9DEF562D0A526CA14E80601FB8F87436B67BA681D0103EF87D0774E7687E569C20DDDE5910C87E5AEE1E56D5EF18016383C097D60CA75A7766B6341732B10F4FC91FAEC2707724F5C45F4234BE8CF5A2ED2BA808336A1CFF852FA8FC9BF732B798EDB667E0F8BBC8CA282BE8ECD3B3010668F3B87762F7A0453D7CC52A5FB99A7B50D8463D557CF28CFEC00A31E81F57CEA787EA01800AFBBEDDBA8B3DC902001F4C718678D22D48A97508E659048E002BB64D891001559ACE7B3DA3F1268034F8D274A4A5E9E202C5B025471B1ECA4614F55A19C16F218F6066EBBD2B324E95EB061693F3EA62135786E4AB16F387C1D52C11E73AF401156635FF04F985A42236E301BFC1F53468D3DEF5D72B030EB84C3AD3A8F18E1017AE06FB8D6747DC2D157A4FA662E3E2078CDA593B99917A7B50DCF37E85A457D3B87FCE200CB3522D7BE11029F37FF4F150D62BF6398E4A143B443C2F03B6F5D33181E4184628FF33B183B14293CDEF36680BA2E9125B4BF5E5E26E4AAA65A2D903A8957957E822F93F7189C0DF89958C22C3578C55838DB9079CC984C7BBE0DA65DFDBE06B58FC006F850E8EC6CE929509EDB66FB7023DE7A82B08CE8816C422D872B8B7812EB7EBAC4CCF1D68E09E3BC5EFF4D8A7E691DEA909E01EED97A6BEF6E876AA7FF80543EFB3D4F96D535840DA1DBD765241B00B5D08CB5E4B5B4B7B4F942E4E3B84E0BD1FA94DC892DEEA546906AEAD8B4CA6EE6164474E427566FF96889939C6620460464C59C7576A05FA3DE62B914335B837D3BF3E6FAA6E7EFEF007402CDCD951D7E5D49A85ABCB43625A659A94556E33F274B9E83F5A28476467FECA29908E655A9B9FEC748787A17C637B2394150A6C5B189AB5EF52DD282B5D6FE81FE0075EDAB6DFA40E60D46CC306B1DA628E385302F93BB97F4F752ACBD37630B85789A3E33C817B1F080DB457219F51DCA8E75CA7E37D73007A4DA0589113340EB36988F68172E72CAD01F53EC7FE6D5512ABA0A47D963E319DD28235AE37B5FC057FAE49312DD3C5FF95AA45A55F00527B9A918A2333CE249F9493596FF9E59931F38BE8AA07B09C30DF5A81D7367CD53A281AF04F2E718872E0A6575C7349A9ECE0E71C9CDBE163AB230F7BEBD5BA80084EB0B476B491348172480363A144D46F4BC6F23AB18FFD013F514A51F1D8091350D37BD61CD17CB9989BC4FA5010E80EAAC98119506FC9BD40CFF3CCCA43D394D1254B0475E5F2C075BA5EC7547E25940D84555E6D342015A74C7F2EB329AEB434388E1835FCA54F44CA1BB5A9F52BA5DC1096224D75B5B096B0FB8F8F131FD431FD48E096FB59A385E371391CF86F511DEBD787BAF3CD36536FA4E4E954BA5937F20797931621C5531C83400CB98C39691B7F2E8E5388200868AD6477B575E6C55555471E99886C52DE05E470A16A62BDA4EBFC015A3FF94235BE8094146608C3A60C0E58024068992E209158BEB5DEF1A018CC25988811A1273098624A119468CEEB7AA41B72D1EE710197E80D831BBF9B7B7AAA29C9B5B41285B3D7EA0A38878BF1F58BDBD4AB66734027E9114370729CA6D563BDDB1B730A36ADEF665BCD47EAC1859F1D3C671FD5C2E444BEB60E2FF4B3F1A2E7F576D33F719B64B09F27D346A42D5F5EE908B2654C7EA6383BD67403DC3DFB76FBB4D92D34DB92C2FEE1BB02E08292444183974BE649252C511239F20922AE0D9D8CFE033E304DFEBDE299A2FB2B16E0CEC0F8E83F59CE3E5D01DF66EB43D6C49CF19F6B6E13D471637101C93486818BC3054AD1FD8E53A7863D8CE34414B0D2A2EF82193C914D12AFC3939E0B9A1583546DB0C62125E567C11F799166835388B0305337D8C2AE3039BE7009657B83ACAF0DD943EB47D5730827126EB11852F0DAD501E13F7FA4174695076157470252FAB07A7FC5F679C75724AF89B0EB5FBA9A88BD825E126B98D3625E2F167516D1E2B9682088842CF3D7E91CC5C00BA382549F98C4DA67DD42DDC3701358B9B48544D1C1ABDBAE46CF86BBFFD85EF415CF51F343C9B85E7542DCB271F466DD864C5CE560DFD8D5C7C66BCFEB20712C8039518A4896EDD70157509428619A558474477157BD1A477261BC6545D924B3C40307E113513069A1A2F66D1C8DBE8B56A9FFADC773A6181AD16BDCD0CDE1954C86F672D5BE0F00D8A22CCD4AE6A73D134012173AE1B75E72C95AFC5D9649C058E8252A62F8DF0F7C65D5614DBEB381D753CE377F21A2391071A78801C4EA3FCE7ECC8287816AAF0FF3062D28EBF614FA27AD3F9C72E0F9367F8A83A4B83965FA2FD03AAB55167C32E0001E3DBA8AA78A80004968057C79A8162B2FC1FA39181336129D0E8B4E028C247EC8982C0FEFEC0930506713A64F504D58DAF9FA2430D3560FD2CDCA7F06E4AD0B1996A5767EC15EA001AE9239E6CF8B5F0F2086FE5520657C0A3A39E3942D5031CD14FB5A11E4B649C1A595DF675FB30FD99CD3EA341F1D3D1B51853A6B552DE1C54AD2B83CC1CC9D06503AC66655A1EEA0FCEADB41C87F9D03A90421AD27E2FF17BF7165DC29D38E933205DE00D8E752138E5D3A42AEF8702BA05F275417B56F0FE7C6FCDB9013063B556C09C768A2E28D6FBB7B61D37F7A9FA821FCBCDDA4DD78B80686CFB584A1BD5CE5410112A8E63E7CBF1A062FCAAC6A30707B153CE8C49A87987CE9C357138504078270B6C05BD9AFC5D77717CE95A45031A6301E7CA966380BBB65CD7F9DCFC3260E512F49228C01E1E22ABAA98DD6B070D85593EDC77D877C2583BDC02C74D9D16183700A8DC552D86BA08BF28B3A4648B564AA202CDEFA60E428B2AC1E8418220822064BBBA3E551657BAB08B6D19A8D25E07C0B0C05370315059F9B22BA20D32F3D80793EB086F98DE2CED3D74A26F897470E34059E0D779FCFAA916D9E638372E5876C56CA9F6AF509C1FF6B9D015ED5126040B178C99B8CD028E37A34A9EEAEAE14E829A84AADCDA06D3BB004AE7E05C75D3651C687901661C74CB9314C89E6CC137DBB4633A0CD19D429E239B883FD4BBE3A99A0A696D3C1C2075532A44A90ACDC03277D679CF500B9079CE47FF8E996842FD826DE65E641260593DAC6E7E226BDA478A893B4C0DC403E748795240C2A439849EB0581096508D102963E55179B48EFB9DEBA7487926A8515E3F394553ACDF0F913B28E997991DF55BB2206D285C2BD22EECACB3EBD13C65116D4E1FABA924330EF5C044AD24BFB183BF14C115E5A32586129BD1543A11219CB890CF73A255273C759DD527A12E2F0381703F9BBDCC6A214FC61249D5397F784A3229E6628BC0A354421968755BAECB36B1DC66E1167CA39D25A044858C5C0C9EA8330FA76BEDAF7F1DB1C9EF7571A87F656E4D74E08F5409CBB39F10716E5B5868541819CC1BA19699A3752BF11090B86CFA9681EDF7ED7D56DF36AECD4D821D328A8AAE906248644A8F183D92DA8D32BC139039A859690F44E453293C6A56CDA54B26F3BEA80A148C67F1AC8F9070495D1FA65A8A9DC563500BC231A86E1E0DA573B7588598A48085BDB606118EED18160843A114E13FE0617C7D697F2A712628F00F52A23015E3231F0E9752384B524C13B1D71126094D8179F8883DE58655F0A01501BA36DD158AB7D43B75D9E86E8C42F1EE4F19BE08E2FE624BE9579456B1596D6656327BB798206845FC3CE5631C66FD178D3CD23B81067CDAC0998F339DDC67EEA1B1373ED846D12D3ABF2070001B6FD8E2792C591443E7E6D6AFA6FC48A70210BDB218CF11212690FAE39610899D76A2924718E9855EEFA6F75268F24CE01364E3167EEDF866CA48AFD150DF24743358CF0D70D54007DC05634E5DDC94F381039665D3DA999EC69FB40FF8B72552D081CB97933741DA0A79CB1302AFF1D94A4CBD8E7EE6B668577BA531DFC06DA620AB36334CC92025183CEC5A022E5E40179FA08C0B1160C61AF80E27E25B30E0F27CEC83C1361D646930E65E57FC71C9C4F00C8AB028785BFD1D183AD81CD3EE3DCDFB9CC569C756B6DA3D71B9BA521F0CE984EC4D710C34C881DC9FD61F1B5C0D7C07DA76973E74B793596CCFE4A615B30DB1C4E814A64231456B4EC47278BA2506DA6551B385D1015DB6854ED2591328F8A90E36E7C339BA9CC9316E085E208A9F2666F35BC68DC3282E7FF223983B92DCF1C00A9EB2F68733B1388EBE3F496501354CDE41BF04294D150DE14945973564839E4B2A25022F911B8795A2F69CC9BC8AE99E844B890535BD654D03E119659C7B8FFEB171A7F4E0F501F2E1EFDAFCA77CF43057DF01DEEC7C16C9373C2C7534A35445753FFBD0B85CFD7CBD565B95C12454B7F0103C169A0A79C0F292AEB5F153DAB9CBA13664B514921732D99D361C5462EFE85EA9E6DF9576178155A9A10A6DAC05B4EA2A56B42B5B1227FA507F2A14A427C617E8269270FF59706BE36397CE5E5E1DD757D4FBC8F88447C4D3F42270569537042CC5EFAC628134BD115487AB3CC70E996F947ABBC7885355BD034AF03DDFE0D204293465FC8EFED438E01893BBEA9F75B6033B471C15F656C5403B94547C94711DD516BA04FEE297B70CA8C5F76301808D53A756CA1782E9EC76ABF9FAA53753DB8114956F28240ED3E744C69D11A3D98E6A4CFBD0BCAC43F5856930296E5C5AD8AB36C6BAAAD365C1EB8C99B17BF9CEF319F3D59671B0E8522D9AE8224F5BFBEE8A9C59CF4F525F961C86C33CBFF162EC84D0567835EF42385E")
 	if err != nil {
@@ -116,7 +139,458 @@ func TestXMSS(t *testing.T) {
 	if !pk.Verify(sig2, msg2) {
 		t.Error("XMSS verification 2 is incorrect")
 	}
-	runtime.GOMAXPROCS(npref)
+}
+
+// The expected values of these parameter sets are regression values for the
+// seeds of TestXMSS, not known-answer tests of xmss-reference. The hash
+// construction they use is checked against the SHA2 vectors by
+// TestDigestHash, and the SHAKE digests against FIPS 202 by TestSHAKEDigest.
+func TestXMSSHashFunctions(t *testing.T) {
+	vectors := []struct {
+		name                   string
+		skseed, skprf, pubseed string
+		root, sig              string
+	}{
+		{
+			name:    "XMSS-SHAKE_10_256",
+			skseed:  "b041bf7ca73cc7905aadc1b6460da2e50206652e3d57a61487beb09664da308d",
+			skprf:   "303f46b2e4248b040a74e7f1eca3ff288b452ef4910739b466225ccadcdb3aae",
+			pubseed: "115e28fb34d6414cd7cce714895df8c4a674ee1e557f1f3df99370aa8ab7c404",
+			root:    "6bbeaea90bbf56d15e2b58259764e23c979210eb107e21dfcf11baa16f161b55",
+			sig: "00000000341e8654be7cefc4e5ae434588a9548dd92352f060c8f7343cee6a8bc56d214c99dfc76cb182e25e825883618aa03757bbdc7de9cd876b2c19138774" +
+				"ac84fe7047211c72c9a65126d8a309ac87ba1c3af7736d2633ab0d39f160643870e81e8bb15680688950e9a1409dad6e9500cba298470c37564aa64bad99bd5b" +
+				"c726de138d47dab07badf5cf2cffa16cfa37fc63a08261baa8a3331218dd4eb06d07f8eab72a2f519256a21972b030dfb8b23c8663facd77fbc89f9df3fb5503" +
+				"d08005a5e4bdb470090d07d65e10e054a79ebc09f7f04f02a51b99b73a00b8a8db1f7ed581662f0f37de6d0dbdbad7d6f61aacf1b30a80fcbfffea198fc5e5f9" +
+				"e67c6ec7a97bd16ca9874688bfe9f1311b8a20ce46ae282283454c16cc3ab206f383513b34551089e9caf0cdedf89a9b416c9485455f36f005cf11d425c01977" +
+				"a3f17c5d75e45097e138a30ca6446403e8a0d3912f179eee958475a423319a96a214d7eab226cf8e81a109b70815e3c5bee4d2daac97d519cf936b10660225d1" +
+				"d08a8bb85659d34e77d8caa23d8fc35d42099c8e115ecc65b586c75134fda42708e17277e56858f44869c31ad6c306a6c481d9d198a4cdf0061eb739d16757f5" +
+				"a402d0ce7f21908231a2bf620929d9c70ef6d26acd484a99fe605f5698fcabe66f0756c35ab1db192e2508b64d92d5c39b2d242ba08935d5487c66ea113f5491" +
+				"8a12cf07b81b8d7064f4e87a7075c871c5456ea5ecbebed84ce76093395fed24a5a66cc5640bf916b700d9cf8b99483b4515f87118c4a86badac0d1bdc6fe2fc" +
+				"26a2fc3f7dc6c93aef9e53f8eb3c0ad1b99db0b208c38de080cf27b8359eeb0476c5c20d9cf914e65ff1cd230b152639a0e08613d638c1a65d6851626d3a3ce6" +
+				"89fdc303dea24a1115c14bfd7d4d35b4bffe25363fd09c20b728eb15afaddac8e10e8e4897c8f59f030ed2851e14ae1970170a4f275fedf4c4736ba3ba72037b" +
+				"0a306c78213f128be06cec96710ba751d33cc4083b5ee288ed1b86dc443357dd766e90e10eaacc0fe623790e158120420dbdf3982d685a9e5003267cc1f7e0a4" +
+				"a0953e56d471ec743c47129caba117dc2d2d70c6cc8649d69097d628938eed9acb68cc0aafbb88ad4bf159b58ca2d92c3d874860d81e9acebaacd9662f302b44" +
+				"0f9d800af1ad6877e4a71f8a55ec98855a8e40d44643019985076baf4ec6096056e990479488ce9da38bf1dd22e969c6e96878a309ca24af5d6c4b44c562dded" +
+				"ed566b331c3aa8e09bb361ed8f79e7044831ef6a31e5f8d321627c4a2cb02ccbdb6dd9b20ea98fecdb77a942737a76df4d2c843885d861e244038b57607b0943" +
+				"fe92fedfb5ea8101a26448dca3102421d1a29aa86a56042986f0f75d5f12ba0c83a13f9dc7e418facbe0fa3015b2d7749b6c9fe19ddea182fd7f47974294e64d" +
+				"b8518a7a448561eed04d5b4290c7a67279a3f297919a8880f133c65804fceb5e5a4947430f0c82bdb8ba8a57a356c17c01958da80bb758904eabfe3f6e78c4b9" +
+				"d44628ce31ffdfe560c02031c369bd74c0de1588b381868d44801e08e48738985d1d67c12fb3a1493b5d96d482ef05b6323ee7ab98eddb719d93c4b0dc22dcc8" +
+				"354fc3e7ecf5242ea4f6f3675dc97de8899394ad0619a22056acbdf24191b0c6ad7d4ba34f7674895207eda235120a7cb6526c85ebaf50b29a2381110155c2a7" +
+				"4805201b0238215584249fbc56bf426a81e5e3b24d9b5e73f0f31d7b82b46e77b9dcca2256e90fc8d05aec8a4323f355e6edd8f7b76ca9599a959b7c877458e6" +
+				"46598a3af3733f620aa6e8b7dbf883378b3bb659bab29f74c3b40d289cad2f5bc7b0d29f76ead452d7ddec2476b7b104cf83262868ec4656f2655ff506492647" +
+				"9d5b4bd4dda30c4ba71f7ee39ce4581723f648d0ca3e636d1d41010408aded75316582977cdedc52c5689925c15112f495417a46aa2816949e62b37dbb21ced4" +
+				"f2f86c93c7ac015360e3ca0d53f1c3a813b4bcda4b4edd9a434f08835e32aec8f05b2aaf93aa476b189c88de8a50ebfc27dd45895e1f2c4b4ab7f0047ba4a469" +
+				"00cb476146bbf855d620adea06325d8ee7505c2c9a9c6838bea99292c411a4e2b25bf2ebb34d247345cd1c1f6dfb0dd819e7277e30c7045395a253cc6443561c" +
+				"53cf03bf1aad6fb02db47babd416220ed84818936d74ce398836a349703ca7ec5297718d743f7d431a6d2b0bcc81cd483f2c21dbba6ddf2478c6c3996832bc66" +
+				"c5bfa6eac5c72ddfcca138d4826c5e9f2f52b6389783642789e52697e64acc45ef56e476a1a23726a0c53c7407435af86eafdc7584363fffc787da8372bfd8e0" +
+				"13d0d6762e3515fd808eb5656072d999b26b5e1de6cf84410b6ef0997e405888738f22db21ab076e100b28a1d69a049805f0174997352c4aa266f629d0c46679" +
+				"0fb5a68ae5f2a938c96820283a458124ba72585e2b0d50ac06c18707b7d5b39c0742c89f8c8dc54e89c2c1b2e7d63b20576ecc45b96e469120a25de1618b0b54" +
+				"0b35bd6d943aa414228f2b6dcaeda5ce385f70b503134bf907cd3f112071ffa5bd88b58fcd37aff0c6b8ca19b56540d6ef3115e0ff0d8e1d6d877d65bd0235cd" +
+				"8647209e680222d7f7c03ac42c18763746bc51117008230cd99502a43628056daafdfed81a3a9c8360aaf6098ada5558d668c93384e891517e1fbb0b6d3c1f0c" +
+				"8711da97a1ec64108529ad2ef32c4ecc0acc8221b23a1724a8422bfd2979e5d1ee19bd63f3421ad1da41915a1e02435fb654c24338f1e61d36413cae0100e8d0" +
+				"2e64fd673fd59278f695c40e0e184f0861c7a9dcce595b848776279bcd6179d489e14b9d104e09d893d5b91a634bbaabe165012c9070bfd2be69ed40e16c9a4c" +
+				"09f2158ffb0dc52db96597040de9fb7ebbeb03e46179b48c70c348d2b675e53e4bb1c77b927fb4b86d5d3e0a31fd87c76c3e6760de3f8d494efb64308c14bd7f" +
+				"339f0ffe0ca41b1ad89ba30e95ca07d8e5f29e85e5332124e9f76b9eb6c7dfd436824ffb6542a95d7118663886b0856e69c937b488592f4a6404faff0a61e343" +
+				"79238d49ffebd76d989a219203fa6eea02d69d5d7b4d99de789e92b1ae72639fceae62e7cb618c089e3c43dfd3732111815b28b240b042d8615e32bbeab903aa" +
+				"8c860ac0db3e507b74510dfab67620d214b6fc975fd724aa8506af5465f3f0a273836f127cd327ecc70822b57ecce34c202341dca13dd38d38b76514b677d306" +
+				"8f117b8ad46c88b27f8cf70368336d3b9ce78f0a82df32bce480031ecd68c11dfc125745c9e90fc259aac9db9eaed8e7bd6c0f90bd83d22ae2141eaeefe596ce" +
+				"da895013e08973f588333abe4cbd9822b157876a2b0fdb0c6446f638175c370c0186a93ab0af6fc5545e868da4543df540bcf99dabcb738e940bc6023a88ec01" +
+				"6d6a7978c8fc31bb6d46f7806b0a4bb382612c91e7e36d693d1fcd592bb3f1b77d4ab25874e52ed69c5041afdc58fed389b4cfcecb1e608543ca01a829f31778" +
+				"3efded6d",
+		},
+		{
+			name:    "XMSS-SHA2_10_512",
+			skseed:  "b041bf7ca73cc7905aadc1b6460da2e50206652e3d57a61487beb09664da308d303f46b2e4248b040a74e7f1eca3ff288b452ef4910739b466225ccadcdb3aae",
+			skprf:   "303f46b2e4248b040a74e7f1eca3ff288b452ef4910739b466225ccadcdb3aae115e28fb34d6414cd7cce714895df8c4a674ee1e557f1f3df99370aa8ab7c404",
+			pubseed: "115e28fb34d6414cd7cce714895df8c4a674ee1e557f1f3df99370aa8ab7c404b041bf7ca73cc7905aadc1b6460da2e50206652e3d57a61487beb09664da308d",
+			root:    "a98fb7646d8c12179950c5380f1f7924249e735406bac7478a9d5344482f54cb67528c174a9ace0aaa92108cf8243f7e7fef308619bbaad5394b82af9573d56c",
+			sig: "0000000048ebd42d589ce1a4f9c476e475e22a6947517de54d93e751604691fe54250d8880449f5611734e4bec48265e41df6d066f197ec6c57e4f5b2f2daddf" +
+				"eba9556d667c73fb47495fcda7e1ec5398f0dd1b14133be3484e0052b8e3996b8b81556dcc900fcfc9e50bf769da76bd655d5f54d051e17c8f227b144684c72b" +
+				"0aad406d0a70a600e04ee7222c19746ae1e19fd9c34304b059f3543db6f79f64c4109e728ea5f8ad217d1c2f402074b3ad10459b83fbff630756c075402b733d" +
+				"4cd6c6f57db1f4a4394bf0095c6d42ddcaa2104eedde8edced06599fb00705ffb2024c9f9107cc3b6325cd2532ab5b734f19cde1e7b8be45693f0b83d92b94b8" +
+				"0122bf5f74bcb4b5c104db2ea6335d7a3f21f3d4b90eb48a738457c99f349a91e398559d8fbfed57b9513ce2e4632c3bb1d46e26f4f2f96a5e4b425e49016ca6" +
+				"e1f8a7b081c1cac1e94da18542cdc0dc884a0c3289b5a7e8aca98b32088b3b14a62083cd3946e0cb0f6890ed7b878720869b69f94d36ffaf05475feb2fa8ffea" +
+				"f9476bee3d41b3689406081d866c900ab3d150e50d4947add74de5eb327542d9f7de60772b96676f0db7e4d71ad162109db1a6e44d42bc3c2ea2a7ef5803ab6a" +
+				"da4e8404b34f2de4adc1c6a99a5b08d429b7558374028221a3402612019397bcfdc180ee2b1e9df95991e764e7e8dcabd6ba201a38b719e998a204e647dba874" +
+				"4bce9a68f5143e36b183cfed8b9b21d16f083f99e5e99170de5f27c80f1883571264dd1bc2c6949ad8bad6a2300715ee649fba6c7080746b85e7a966899262fe" +
+				"946df166c585d2a02ad8370207b38188c062ce28530c0d0f751ede2f7414225bf93aedc769c4e69df21d6f4f64d5660b6532163cc26562ef8be30af8f3a3f42e" +
+				"ba762300906df24e2933b9ea338b4d161084915c63111734c26fc405be2a1e80f46a8b732dbf54bcd94c2ff555326fc338c38088072d1da65ab12a5a2a03f2ff" +
+				"b98d416e019f8af63bc887660ed2a6f506d376ec8d0df10c352248908e713d6a58cfa6b0590a2a051150dbea664482ca39c0def794c01167033fbab8f7c3a3f3" +
+				"16a8694750fd1fccae08edb7b279edde73c6a9cac47b49660c0ebe845e61cf1ddd2e544aaab19dd7100c77a0837d8a7e27441d953d97e1a5f1aad316bc059e2e" +
+				"2e660adab9cd12a51a989f18d4b67a55a3681888c2dafec6fac6ff0c2e07b131dd4496ab8e9c8d749ddc74af1f8f4cbda454b50283e3cdb682e2ba0dd52d85c6" +
+				"e0afddb29ebb2f70c78fff8e2ce0c3593573f09b6c8628eece7d607415bbe52980425bfe5de7dc07226832d5bc9471d831e535635eaaf46cfac531817dc2ebdd" +
+				"5856b9ade1d55cad16f15ecc5653dfe0b5a87c0734a507fc345c13434af839c4ffb8b02558808b0e3aac51651c2c1d287c23adf41a4a7d83f81b5f9f99adf7a0" +
+				"dec4d4b105ff818cebb50ff83740279d6b492d8c25d0dfcd0fec803a67221cce56c091e7c7ccc47bf79861e18828a84b557604190d65fe9ad980909f2ff24e95" +
+				"5effae457f2c662a249ecca2846d0cd50a09b811d4bd0bfa1700fe59664fbc5e2275934aea760810e488cb5e9f81fc698888403662e69c1d2181dd2d47509234" +
+				"2dff269a9715058b70fa153e5ef4622285a440016577514f43f23cb40e18161dde7b2845aa4768e8de0070ecef4da58823153431b6dd54831566748bf5e8a3ba" +
+				"5b15237489d5009e92f59ed5c053371ddabff5e4c1606031d1aaa06ea1948b2c4c478f72822f4b89389a316b0990456cea2422b3f2d882f081b318e394f2cb92" +
+				"ac1e376d9423a8d9ca147f9c6921c494573297770e6c2c66fababc8f31afb2c2eec5693bfdd7495d8ec480a88852a8ce7b9d66b5aa5fe2077207018841ad8a97" +
+				"a5464be49369815e830f15951fd3863a42fb5e986fe79faa745e3196b05603cc794a0ff023740a89f1fa9706143adc89ab6d9e7690f57dedc2591784d8d294af" +
+				"914184a83e7415fca4b2f81ebc69cfcbd62e2f0e53bce9b5e03f743135f73c632d3fe7c4279eecf98bfccd5d1fd631a70e1dfbc1f524b5ca8093b7ef87716931" +
+				"b555249b8a50faee847ce7a442f88cccef507bca29d14e3b8c102a71f672e71b2d2cebe0acd9baf1683c174cbdbff2f2fd653e89315c7017a1d0fc30767918f7" +
+				"76992af4f9d2cf6dd53ec1a936b81a9e208a87923c2b5e3ec27023f715e19e7a74044018ee96ad04266ea8dd0d260a64fcacaab6ada6e553cb107a3c0fac19bd" +
+				"fb6eaa67e02413e25ee3710973a7a4c1d33b25ace32a571ffe79dfaea99c31e162f42c28e5282e63451b7879fbd635ce865c689b463e2c3e5a208761f2742f9a" +
+				"96e11c14d2e7929847f7e9f88ab33d2a3177d9009e18102571a1400ec9b0b45ccba1b64f528ce802c4dc37632cf8b45a9120c277208ea2ae501e237d1e09f139" +
+				"aa8983a7d878dba47a3f608106215409aba1b798be50a9112e4080639fd5b14f93edadc8937ad1b7d6bdef99d544a228c130f2cea8f87ddda2b0d194f43972bd" +
+				"34ed052733991a56ab0ebeec35936c877992d35d22644a4673ce0589d1440efdf7b300958489f40df0bc3141d1b225d426fad97db0ab51fc72bd5c02352c4880" +
+				"6bb95ca22238a2175acfefd93ed250f67ddf4649600d560e6d96b53582994266c1ba932236018ac8b870a4bea792589c53bdb9cd3cbe25a73103ea96253223ce" +
+				"9b0cdbb9b14ca6f6e99550e2a1708ff713ec13f93d342882b6757635508d8d3d0ebb3c95b0fd3fd271b5b7237319d7141461e49e28b9e7e015bca79e2ed561c4" +
+				"1556586a94244226d9885cdc5120cb031c9427ec68dfe488b233171dde6c3e2283d65d536daee655205b9a5daf4f783da706ec30268045e461162cc676370c87" +
+				"70fc81a23868ea321bd68e515e38ef6e6333375fe10277275775e94c5aad35ddac2c5520fbad8a3fe42b735bf21acb0af396139e7e42df57139650b46bcd3198" +
+				"6640cbb7d7680a448c45be997620c7b1c32f59f9da5e2fc4210d5b9a5125574046f5d765af2f51f7ef90f4d07939bc1cb033b54b93458a45ee30172f197d1be9" +
+				"66c219d34431263d3a226b57d377e32891d4024d7dd22b224b1840c6b793bc1a7d81dc7f1bf749fc74a2c632ddff7c355743a905f12ae65c7b27e2c9e6fafae9" +
+				"b9b0700a14ca498b3004b2696a90bc2c10a95057e2077134da38881a4765e16ffc59959167d298aeac4f5381658ce035451f33a0ddf73bd59258e9f540c73452" +
+				"f3adf7b128e8ec9b4ca9898f3339ae00d6f7b72dd6f6c044e7f6ecf952235a85522ed2b14a52560fa913e0c228df35f8af6b9ad923d6f3c41fac23cc66f78187" +
+				"9c05ad42d4b32aca6cdad1e0367eaf029b165639d3ed1b26f61356be5ea805f4546a63eb955768969e43881869cef54e21edee296787ea03f3c080d7458c9a0c" +
+				"d59739a805f92bc4665d33102a9c5951e6df9a67375cf34642cf21c9c7a4674d49caabf25c614c9cb2c1862408c9d56fc5c3122de7213a28ce5cf258e402dd76" +
+				"70d5d5716558ea8fe4b6b3c3be2803996f7c340e17fdd529ff4ab4224ce8049ca4bfa6175959bcca0b37ebb3d9c4bbf1b866671cf0b19ccbe6759f955c25dffa" +
+				"9c3b87a6ac06b93752ca583bb4fca428c425afb9cc970f2be2a6e2324a872875c5a4d5e5932e88d700cffc3fc703ff32fb5902cb037ec1b570906e19b9145f92" +
+				"8728e90573aedc252b45cb0506bee7f48f77ee43c0bcdfabbc8590c617487574f2ba701595fa76fd5093aaceaff34505542c57732c60fbdb1711f70554fba17e" +
+				"c621038b1bc8ec0e164639b45ac75eb12a86e2d9449365b95cbd7bd32096b78236315624561d8964bb717b51c7d7d45a6730a70a91b284392b719f2171dabe84" +
+				"d5f5578625c6861d7745e915dd1764cf83838a545d19ea2227739e006249f7bc89727613bdde74bf6783f78598648fac3a2443d2b8e3e907729796224010ba5c" +
+				"a2d3e6a7617e00be4adb6efb6058241ec4009866208e6ed19ea163f5a83b8130a89847bbabdf130adb5610c2f0612d39b7cd41705651b87cb2b176b786d386b9" +
+				"325f0ed2d4cd8a7fdafa45f468d131c600909fad629975b7a0c89949696c26850b6d1b80e938ddf4fe43eb77b93773d296b84fb9c556ec70811826754d086523" +
+				"336aeb29df0c069e12b6f199f5f9f407fa0889849dc3804d5a1a82ae5d0ddb6c69c23b4d60a85911779f3d6f09c7cf3328398e74972a6d1c4a1d13e93b08ecce" +
+				"64edd598c1327b0437381aa9be76e522dd9b89562937416b1c2a40fd52f640dbbc658ed3d3af61ad505fea63026a96114c12898766ba479ef3689225786b60c1" +
+				"184decbdc625c068269dca1c2008696e7fe28aab788bc8d3c957c93a8267f478fe3c0538a7d1f466590df67c129af98948a30bfc44c3653541725bac3620f027" +
+				"db18c188df4e402c759c53fff8c9bf1bcbbcc7a525b6f68e09c5df2b46dd5751d62f8ffc041651c4233b7911c345feb62368940243d1f987285322027024d0d2" +
+				"a7a6fff20e75c373eae0021af9dc7cbba4094399a6b7b7ca82700237cfb7ff9376f0039bdbce739e4abadd23bff7bd9f2c55a36ce4674c4e12d7eb90761b1457" +
+				"4c96ad4c93c6abb0dabfabdfc760fad96aed89815346abdb866c16c83981493ee41bad512d4a52dba6b735ae4544bb3ec82c2ef4eec3094485e71c7f9a8ae4f8" +
+				"ce26cf3225e7ce94acfd40560cc866acb3dc2f586a2a0fe5d1717f9aa5ae0db597a6dd4b0e365c745a98e3018c92d2e373baf97376c8bc0e94a2cf2c3c45ac63" +
+				"90d90ddbcdefe65c854fc784520036c3bb9c9dbd007d8b3a5c40db8d8803d4c9eb93a80682c008176a05222c7149cd09bff935b51dfcc6d58b5b73f4681cf388" +
+				"95b44fd354871a2d9fcb118b4dbfc895f55a000c2eb684883188097ea30886d242370d623967dab4408a62bcaec362e1437a5c48145fdb3050a60f3aa937da4b" +
+				"86633abec1e60b5a0d8104071d9929240b2577bf9d8fa98b3dfc14226167229729299ccdd9054f25982d2f3724b0de517aa3523abfa1d4f4f7ed49fa6aab2465" +
+				"62a79571f742f09bf3da1c59df50b19dd6ec1d37beb1cb7093c111b60e3c1303ac715e41f60f89108b6340ca596956168ce11b67b748651380c397e87b310b76" +
+				"b4c6128566103e30d13ebc61b5200f37e4f50021897c56f0ba073ca04c790a3b8e541dc4dc007609c510f410a68bc8059b3284d0a256752c027f8e56e83a6355" +
+				"eba7a155ca098ffc37089a70d4d70edd711f4857eb2562bdd8270f6db841d7472a3bc1bada03be04c7deaaec4f1abcd436c1bf3521c81e2b3c6b256e69dbb3b6" +
+				"fbbfc5c20cc30965dbbf54a556803788de33f352111b767fd9da78dd323a5f09fd59595268442868b2d4490e8b9fac2a79b41ddbb861ef9b27b9052d36b7db74" +
+				"5f94f53c9f287eabf1f1dbaabeee0151a50abaf9810f1be7c74e7903b5c47fc5d0a94f6f2bbe84f54150d2ed0619ced3b096aa2b4640edc2d9cadf7177c04663" +
+				"de571856157265aa973aad012cc9d66838c9bc31175fafb3eca1a8db973d5512210fe7547be9f2f1fa75133c9e0d8e9c2e456a604c8b5ecf8c4fe2ef9c65dc3e" +
+				"34f9f5ce30913f066cb378def84bdc9bb2e761a4052558c938ccaea28b901e16041398977ae0b84a7405ff1beb9f80246f07ec290a4ead56de4f9c057b7e62e9" +
+				"5dc7166a62a42c1407e31e9e2e1c5656c4adbcb8efffd1b791acba4474f19d78a35e81b76dd41ae8e43ac6c803be5689cb986c5c60a7abb38650297594eef168" +
+				"ed7d33343f3ffc0a4323b9c8247939077d7c7960f874f34be275d646d579e864b11548afc90d4a584508eca3e0fd9d7522214e7690d2b877a98dda234ba710e0" +
+				"2553a002657eccc1a595887ddb33897d4369180e7fdf8cbcc17d86dd2470f5055a59e488d734a9f936025b9d507935b22a6b8caf3959fdbec0a228981af22678" +
+				"963e807fbd1e944cb681e6050e87218ccc320caa9cb210f8006a61e5640080a28f0f7f2b44cf8a091e025b311a760fd6167f934c3bb30e2b3346746685911048" +
+				"d95f22f2c6c00be9946e6e0ed8e07fc179342de9b89e690165e2fdb0e68e2f62e0e4314546747ceea846e0a13aa455c726bb61b60fd0636bfc0416c55e3f5fd6" +
+				"818aba8354fc8cd95f80b7312b820fec320402b0a2ee6bd1940bf355d81257c1ddd782789bf804d174e727d14d219f7adeeb91c1c69dec44d1ef65c3fa879094" +
+				"3dd900b2d981db7dcdc554348393b8ffbbc089bc22cb2415fd3fc1f2dfcd16187e6b117321d76dfb294fd4d119189ff9d3e573446d3b32924984d5931bb67c25" +
+				"f1e16cbe986cbc7a9c585c14e169353b86ebdba27de528636b6f34a6be20b9a2b921c0074e30508e594d30940c144d6a14d7cd4a9ea1e7acf910026aa0a50118" +
+				"3560d01dd24a07df180a3668ed590fdcce38ed9875d61db06e83e143ea73792c6b90dae8548635755ab558f00a659aed267879cc0bb890adf7bca96761fbcfd6" +
+				"eba98cca5731252a9cb9cd3c72ead6244e59f372ded4ed96712c567701a4640ad07eb354885bb31128bfd22a3d87614a8d11f5c3c399c81d3f507f14f3950131" +
+				"a8cf407053d87e61868f0929e2a9b8634ad2456dd2036ac999f220b98f24d1a289358fa5d6e88eb6e683ebf5efd59e51cfbd947d8c7e70c46dcec2fc2dcd333b" +
+				"d89e3d2df7f8e102defe2d799e3d015aeb0b10b7b1c97ad50792885dc61c81e2f991d1a36554f5605e3b2d860b69ebefc74b1fdfc7160a2ca20289e5c400afa8" +
+				"8b71d894dc1505827351b855f6e07ee1ef3d6c9928f2a2ac9b90224b1ee744629dfe9b67a7a95664e0978c703b9a007dee7753420ae4116f34f4bd03b8401a98" +
+				"8099fb08db242e16b80e98bab875a5e3bcb8d04878e4b27ac65e774f352bdeb4287ed25b16b6ff1a7118a00f39282008ece530aac70cd9aae5417cb3ab0c6a7a" +
+				"faa8f612c6e806ca649d312367943e046f7d1cd7448f608fc778a3738ae501c728927e4cf1da529d6042e008613a11303c62f35996be5353c1a59ed71def6ac6" +
+				"efe532154f2261b1406b8f1468611e5dbb024b3e4e7d0c467bb97431a5460120d273cae4e9cac0706a596e4b0b27fd1272f691025cf7b0f1ef1e578efca28050" +
+				"c0e1334c2164a80f832d31ac0bc57f32d0cb12179584a6a97632a905c0612fdf1fe36f7939586bf517d11093befa32c98313d75d8f3d5dc6ba108ab5cff93993" +
+				"d6d729613a9e8b4841292511bd38e5e8905e8817578f3b3855b2032eccb53a2e9575d7968565690e13b84961dd1b314e7f05e56f5f76133368c3d6ab33fa1806" +
+				"6334d53d1c713ebba85ee1cc9391f0cd5f2774879c66779419540e85b532461b913ce4386a94e46905c8bc0bbbef72c8c865d70c1fd968bc6f497be5bfc940ca" +
+				"0915d8082d756071b3ff77f122d4670322a49f36f99f96f11ecb69d81b5e55894ae132e8bf59fde423b708809a096aaf915f3f1b9f5c8076f3a7ebe11b7aa219" +
+				"7a2ec416c3cd9ccb7ad07a300b9173cfadbf85c27536de1c28662df074f7452a2c50a6641db4c935cfa77a42c06b9228c509589640704fd4d50e59c250dabc5e" +
+				"b8fa98b2ec31745b67ff1faa5101cbb16342161eb2b6be4021e16a3aa10dca92146b829395584641b9001b2c73074d440bcc4ed39432ef942f950b06a54bd128" +
+				"b38a9b1798402650e467ec3587c5454372d4c6ef4b6628d1e35932b69e7d273c9dc6bd57d5d9ab332677785aba68561496e7fac1cd3616963542b32444ef65ba" +
+				"0311888c3506ebb32bd2b956aa5f1435de5e8e410160a88408767056882694aa2caf3ff34de9f15cd38b77f06a1d9f90c5e482930ccb2ebc7f13ad154912c2a7" +
+				"8398eaec44aece9493848594b642ef2c292f2b7c475b793249d2920e76fa0186bb88c5dab8d0aad22da076d0d28045e31227c4f9aa94b901274641d5d922cb38" +
+				"be5a59eb9b383db14102675cab1737da1f1aab6a438063817aebe2a25f46fb9847a2702914dd12a5b6684670c536b0d2be798116f58fcacf742977baf425c086" +
+				"bf4ca218a116646d447f3a2d24b3531711b3901175815dbe7b5d3242bec2795c2f73b51a505153cf953862076374ffa5072d399e937a66e6173e8099477e6e4a" +
+				"430fda33b79d594e20006e637f1c6190a381bb1c2d288efbff672d5949f89bb6a9eba58d473b0129142f6b08d7e1565b8581da3d8b51dafb32e081cb5fcfd714" +
+				"42f82afb9329fdda9e9bf6f06ab9a2aeef82110cb71c09ffa7062763474778f9f4f131322d87fe74eeb9dfd6bb7678493022e26203608efc71e1216f04e2f8ee" +
+				"aa1f3b13232d5ac11af84d4083ba546be14f67a6d2dd205625b0b3f7ece6fc0dc4d6ee688546ea28980391b53b43d1e6480dd9061556b71adb3d84119ed6135c" +
+				"8cbd049bfd68f3419c4e7e4e31bf4307a062db4b1d176af8708f58bf516ee4a6e6f1387dc8e93c2cb47d744da047e3d0fe4771c0e26431d33e4b355bbe4eadc4" +
+				"5bad0705023a95c3d98a375be156bb1bb3e0032550e53f4f17602f0835620ae835480493c2d1611df4d136cc624a6f2b5666c8b961899c48446362b802d63ae9" +
+				"f0f13e31ef9f0800b61c544d7cf78b51e24caf0da0a61646757d6711c539ef0fb2506592eb5c9940059367860d990f9f8d38cc56d7d2f7c9fbcc7b5d25e8be46" +
+				"3446ba8ffa5c659b7f16e73cef306ce6943f0885b0d9c11ea60f80930a83b4b5bfbc352a73d16495feb93d1c3cff6ac2c4ecb9a1b30c587cc0a7f512f1e6b432" +
+				"1739e1a3c1b8da5dd35040559887fa8754e975a2d505b493a9b85b7a0a53d6057b86f303b232875584513ed3b9f85cd4b9768335ffad6a887254cc96865e9c74" +
+				"c9619ca68ab14e0efcbb3159317fd7dfc1395b20819ae404e1f082a3e284c39e9a01ff7dc8cc6e0cc654f56a7bb7553db837306a69f0db75129944dcd8265867" +
+				"f9c63e9cdef7488c4fe8e7a97318d6e05ce0e8cdaf8500997038c5b564cbe26129c4a4cfc892ba5b9b5231575cfcba8ee62bbae8782cd6408755be11af594fd1" +
+				"16506aa2019f5604ed304d732fb02a66fa198eb0a018fe689aabc8667d5c42e2a0f1833e1d45dd171fda472912e1c03ff0cb34afa326003db6c1b4fcef173393" +
+				"767a95db743ef7791c12ecf2aa245122c9ec210088dd26f358b4f760cdb8f0a5a318c25ff344ce3c34f478e1572b36ba2193ae5bd1e1731a8126933935efe541" +
+				"7d999d614d2ef32912d120239598ceb901a2f609b99ed81f72aa479705f49292dbbfe410d75258e26be7b7b8fe0689578474a16092f4a5175172579105cb7150" +
+				"d3f3aae90c41a59ccc5c0a5dedb98350f9681540e864cf68263c80fcb2272e1069c2055e5ed5701f695d40de7172d3c7361b5f1caeebda4ece6a8a942d0843bf" +
+				"6512f8fcd740bf4badcc6070184c94aabe47fe40e8dcff2db686aadef4fdc0d72c7b107ee1a6f6294ff1bc1f8cfa2a16f84dfd1070610192c543380e12e2ae68" +
+				"acb32e39f11ac62ad126f254ad392d5b931156df0019b82e6b0d3ce879f49a0351853332a6e850b57d71fca5c84240be7ce66226d229883136a9ffbf515c27f2" +
+				"416473b313c4e60a75cafecbfe81590750ae7419c2c6f5fc96a090db3e20ab886a6ceca590bceb941c020b1fed8007ed04ff071e9c538fe562b5c587b03b7bc1" +
+				"baeacd8f7417ec531014747a0b0547e2b63080e82d7de45aafdb560debc19016dc2e1e9615d6135d04d511cae1f10cb1b8f235834a0956920a7372f2381f119e" +
+				"da3841a63567f9d1764d366035d9867db02da4f71d7bd6933d68be6532f2a46107aff78d8aae15c762c49da1bfb1c4801754d096a7f384b2feb2f1330c532ef9" +
+				"c17e1f2b49e28254f975aab557baf00cd4c855d9a8907a777fc2f048920cf1469ace0532c66239092e1b50e948b1505293d4f452864d0da753d65bc579fb8f95" +
+				"13fd41c312d7495771e889c3b460008571751cbbdca1eb010371022b514e190727aeda91208647d0e3353599940da8ce378b48bb41c1db2b8772ee9776d315ba" +
+				"8d0b674418b91076e20dcd96968da5f0450cd13343a9dddcb8194e80cbfe4351c73b5f051ec7b06dfb056f905c4d963ab69d083df92be41d9e4ed0425d137743" +
+				"20909f9233f2aa4a95f643edd1e9a416b8c9e23240d550463b37de15c0bd69ef7076fc16bb570246af1fefe7e8bc8525fc7e422e860fabd5f7c7096f627d6d0c" +
+				"c1547609cb53992e50f32654d532217ccfa337aba5f5e9d564d5b1f9f21c463c522df8d36b5a107f11140add66ccff8522b59b617fd422f68a7394e5033a45a1" +
+				"933ce3c56a45fd2fe8e9bba2a1bd669318c418eeec03b2cb9051b717e307d52b8ffc80a5f745876a7d270f610036f2c328a498e91ac92fc1834f1279b50fe18a" +
+				"93e7c64582e607af6c1c42edf80ae617caf045c0d81c61e36d8f21c37f6ae769c73f4846e445ccf0a6015aa63ed497c1d07f9265c1d876b5e750fb9f33c561d8" +
+				"d2a1df772db418421a4b88646d921f800f413bc1d93356267f935c2f7ddb617bc24eaf9a978538cc83ccdb4ec44b4c4c04139f9d6a2d24197f36bac84df43f3d" +
+				"42f312c5717f915378a588bafe422421f186c3b8fc96f69333d362057b664dd1da888d15aac79d7d69e52973fc22cf9ff7af62669fa7096ebffd7c6d434218d9" +
+				"cb28c42a5c3e7def54158fcc93a980230178cb1533767e0520e6c131e6fe646e285e365ed33da39c2472bd2bad29dec520ca6c42adbea3e775649e232aa4ff8c" +
+				"26ac3395c2fc8b36b8cfd2f30dadaad1cd40d74c7e9031459cfa9d58f16526cf18257ff49396831d318e75cf586aa82b79d01d8a40986aa4a8bb871614268522" +
+				"257cfc31748ef8c9b4732c2380e4a68130657e3e661ccb109fcd5953ee4e6e77013c9e192a771950c04f3264dc745af5ed9d58d2c39076433608d6821af91eba" +
+				"be68da828ef98e1a1868cf92def5922b277e3112367ee92a486fe9d49204650b514eea7b57b237218db35430ab51ddd89b078d90b4155061e64d0909739310d0" +
+				"3a9c39e33dff6eae09b77a727e4f438807158a006aa1b5839b1040cabd47c52046b7a905780b764c7d60e4eb9f15c9bb3c9af729886d594790ced29c6676440b" +
+				"ae5f5d1f0ee533c12eea5dbad91569d751102b49bf375eb749891fb4ab58150c8f1accb4cec96fe1b52ae80861c00af52768b3e1ef0fed18e08f9719d9ff3694" +
+				"a15d177ed8f58af5d657a652f9bdfdcea283686073663baf4146febe011a1da7acf4b1f96dcb953fa35df1917588d3f6cc5adb9e67a264647698ad7f96d00b22" +
+				"01769e1bb3b504e0c98c4696c9c39e8bc992a7b18a0fd40ed4480aab38d6380a5dcdce1583c258789403339133fa134788df3f1a3bc2cfa0093af59ab954e83e" +
+				"ece2f615f36160cb834488ead499774ab55680574bf8c00627a3c0405677d8da87c07400f8b53d5cadaa79ca776f3196a7b3b9be1129778ba4b2990ec8d4be65" +
+				"577c6993c66ebe3c97753b330ea6d391895ca3f330405a53abef0109dd9bc0944712e5cf19bf6ab99b7605c642b0466f62a661c6f4bf7edd3ed5f3d1d64fae36" +
+				"8a29b40ecf4b58147f5435a03305599e0e0e494a20c4deb5fd2b66bda7e11615ad29062a436eecebb63bc1b38686148f1cfdc66287ea33139f3f22fed37a8454" +
+				"acdc5da8b8f2f94d7494183ed0c37609f25d94da5a82b1b9fa42a8f907c8433d616664728fd9b6b63eb3ccf838844538c0f4f86213742e1a2ac12180dcf6e42c" +
+				"879c25bac78a3c3aa0948a96b53eb6ebb7fee10f96cc4f3e8d3be0becf1baeae4b24311ecc2050bb783c0861ef88f84b045e1e18631643ef335630cad5204e0e" +
+				"7f5bd615cf15288bd16cdc65e1abe01b38d5238aaa197c7dfb5daa8e45001988efbc9a738877f8ce5d73269300e7729e47a5674b3d59fb0305e5fcc73bb7c750" +
+				"02fd4ee2774c774881b1fbdf963fa3ec6adc2feaf3d82cb738a33aa9ed41175a7becfecd1e3f8d625f765fe411c2cd6d803d1d59a0213600f2a03a9e6ef961cc" +
+				"7b127e24fdaecd92f60e07d39feb09e7c15d8d9433a5a08c930f9cbabbf0ed2b7399894273938fb0de1b08be7a01eac8ae3148cd1790eeb091eb3d8ffffa62f0" +
+				"df88fda207d2bc9d3fcb76aec9fe484b4e9eee0e8c168a7d8f80c6ffd066bc1c18b42677c7106113296b39d3a6796c2319be935e598a62c21cbd77f122b7e7eb" +
+				"f106ce32d2d38d201bf2224dbd944f34da5d88937eaacccc81036b5b09ab1fd18b8ba36a429eeeaa0275da78e20d7c73be7201e9f64c9c6fa376e56e479086bb" +
+				"4aff3abd2598664025ced37dc052a938b083fda227bec2fb1524dbe8c52b26b7caf30c63b2006072110ece0d7c5784f4ed1a8c38a00bc6c2e1d2b7915dd67624" +
+				"cbd773860bc95fade32300b1d65421212e42014a08fca91979a9bc76407a87cc31a2636c9c61d870c1d1947770d25f29d24b1d66759bb4fa9ef0e5822cfdcfcd" +
+				"5e904fd687b26e9628dbfc15154ba93f88e652331df2238ed2e61f57c2314ed39715003c1410a5bbea7140b3b80cc51f968c3d3762f3011a9c5947c2d5a3561c" +
+				"258d8b2375ad070357d1155c5255c750c3775b16ff9a2e91f77f3eee70358c8140a78de74e468998cf370fe1b0abdf61c71778c5a94f7897a45be7dd9ad607cf" +
+				"ae29e1e11091eae5b80d13a80ed32912c1383de5f4e080984ea881751d5f6ac7c4d0a4f2d12a9fa12560e26d25c98faa7f0ca88a26ed4e5b0ed1d051ef6b7a60" +
+				"bb2cfeaa181b0036bd7ca59e15dd083e427ade373b808e799213f926edd44dccc40758f6b9d1d583d7d18912a0630fd80e50719282a53e4854dcf4b1bf585d78" +
+				"44a58b68",
+		},
+		{
+			name:    "XMSS-SHAKE_10_512",
+			skseed:  "b041bf7ca73cc7905aadc1b6460da2e50206652e3d57a61487beb09664da308d303f46b2e4248b040a74e7f1eca3ff288b452ef4910739b466225ccadcdb3aae",
+			skprf:   "303f46b2e4248b040a74e7f1eca3ff288b452ef4910739b466225ccadcdb3aae115e28fb34d6414cd7cce714895df8c4a674ee1e557f1f3df99370aa8ab7c404",
+			pubseed: "115e28fb34d6414cd7cce714895df8c4a674ee1e557f1f3df99370aa8ab7c404b041bf7ca73cc7905aadc1b6460da2e50206652e3d57a61487beb09664da308d",
+			root:    "9d0d319f8ea76318aece38e5f89d25514aa935f444ee65c276b81de023f4c4609378ec8d70d89899dcd257e08d4de2a8377972741ef9a871864a6640ae0c76e0",
+			sig: "00000000a0975b4a0110f4239151cb9384966e3a293c43160c1557849ed21aa2e652980a852dcc1f88ad6bf29f7ea488c1051d45c6161d0099a6aa1cbd274f70" +
+				"ad622949647f8b40103ddd069edc7a6381fcf829b73e3ea69d65a211f1061a36898b02e788784d5444a7e1814843878688227c827800d863dab7ca3540d37d6b" +
+				"512d8de6d31d3a2e3834ab38f7776e45f3f0821a26ec6883df3e4f31c4e5b596ec2abeedaaf6720124faad5388e0099c6d88eca79194fedf127cdbbac178b48c" +
+				"a93e6cdab7c33299189072bc530f8c0b27d598d1f49c43b81cd8be2ca400a2971422c01fb900086fe003985a634881d57cabd2243e45657bc310a3757ee157db" +
+				"f17eed94fdc1fdc856bab7092ff9828e38c9e694dd21ee47eadb9511f5edc5b953fbaf12847f53dacc0e04b734bee42534edecbefff249356748dbb77dc59558" +
+				"586d1939d51dc6326473b390fb9cd4708f3fdbf237f1a3012edc62f482a426a8a165652ccee5c5c01c8499c5c20b718e0e9c3a7051625bb2eac938b146dac942" +
+				"daa95bb50a91a311a446705f363d9f24a03b567c3eeb103c2e636fdc6a05b137aebe32660d8ffa67d115c3ae951f20634b5e3a8f98fb839e923fe99854b40da1" +
+				"64acd2641aa5c32646e212786af0ab6d2c9a67ab519641643e85429b20192247f990e7c5d944a390385ab4d93ad0715f54b85ce571dd892f3f96201e9e624e13" +
+				"7b381fce915dd046b34ff593c30cca91a1221512bbc6db8d6868f93207b755345e927d5131e449991a10b4bd0f4d2a2152aad167ac1338d8debbea2d1d761ae9" +
+				"9be331fd1f0d9f3dd5042cb94e6822a6ea1a0c5a81a1c6cbf745fb01db0aeffb2d2d0e8b522ba9859cb0f58def21e36eb9ec142dddaebf1a4fedf2f2f53a3e1f" +
+				"d040c3d15b6199fa6250943b06b3035be7bb541cd097cb38d42393d0424cc0e40421676d38ff1a7bc9441db27305ff278ae2f8dcd0517fdf6ddf0fc3e6a4d321" +
+				"16d19c98fc45d152ef387e46a5c138e8185e8363f4f82f9e29e58b1bdbd0726df359e7481d447d958cba574b1befb937fceb5e36590a790202cdab5d8810f5a0" +
+				"515ba58b61786644b68e471f9635a70760a64a8d52a0fc0a4ff01f26c292595f16690eb576ecd7b258dfded7649ec96dd987a5e207c6e6453f7aecb998e1c710" +
+				"58f27bf958beeef0ffa8148b68bdd20cbcbe1b088c87ffe877439cbbd2071cea6bf786a45cc80e087c2491220d4284924c1aa997f97efcb2e7215d171b4fdb7d" +
+				"fb6c5ddff666a79ec4f8bbce628d2cb000f57b9f49d7dabe77ffe9533c0c1d65f1ea627bb6a72d401050ec3a2beee22cbfd7a04d0aae33de578ef2424b3bf5e5" +
+				"7ca3cce182a74bad15aa015204e388be0479cd44bbd7967fca4245f0866fa7596a181cc1ba0017c3513ed7f9d764ca1e7d6efdd943cf9a5917be65a85ba7c093" +
+				"58d137e7a30420ecd23f625c2de625c80be86efeae35e192e3e0a8563d30673e80eaadedc1f07a82a05a2c9dfbcc93e3f9fe68c6b63ead454bcf8103bf447790" +
+				"ea2a450e69e3b75349935234a87be5cb4ceb07f84d9059fb938010da3ef582cd8226161fa842086b517b508bdea824c04350b57f80d830f1e66dd759c1f73e77" +
+				"61029f5531e5e7521a57df44109baf435ee8b050db36856e871ba56bae3856f7664634fc26b4dc2dbef1072b9a4e81b589ad27092a6a15d0356ebe51f466ecba" +
+				"545a0cf572a1658525fa22bb5e8dbd8b82cc17ac8581c371aa61bb56971487ac2bc0218e41e06a63958969e1d30168343f0b016558c8a073bebc54d27d1b9dac" +
+				"84a1b06eda49418a65e31789f862a40a45db5ffae19069528e45473257d6de39bf51c7229e056031ae21c674419702a4a180165a7a4765eff66a185965e5b6b5" +
+				"e585d7e743f90002d8bd378cd8cbcd2228b78ae3dbca4496580cddf69ed7c835ed7f4b33d61572ad35910b3c5aad2c4e8c8460a27de156f6eca38c2e96ff48a2" +
+				"6a48906611af175b78b5eb4704e73ac9ef7aafb6cba372c1ff9e7d0d7f439d6f8ae246e2541c7bcd67880ed5fb2536e602a9ac4284e078b8c644bdc7314cba49" +
+				"e952cd3c4760a941a46e7c150a4627ad585c57e36285dd2be01ec3b3ada36a1f4c41d45c55c2a939838bd24ffc6be4bb363ad7cac091637e0caee849f5486780" +
+				"acd3ce445e6419f96b132edf0bbb11905899b5607931d77027c5e0b19dbe923560c441620a1384d9b8ef935ffa2eec4078a6ff1fc9fe01031208c7240edbab5b" +
+				"d2fa42be1901b412fae485f4f2d239d62c8288c8495d43cf4f2b818b18a1c31449ca042e955fa9f80901e5d5008ec4c46de99cb5800667d79ed07e1e1df0a175" +
+				"47747c58d34dcf9a375f21d2b1b4e602289c930392266c589a79c3bf711eaa2aea7d0525514e00e2bb1d3b40f9a7100f1eecff85cf457abd1bf3343c18881943" +
+				"f8dd1ca066979e0cc2991f457942fa09a511090536f7b57932b0abe7f9c0bec2643bbf484aca13801a137d6587c0c577cfa46a76bad87a7344b89b18572d803c" +
+				"5aee17219d9ce316f6fb73fa6a9c239886849e34989b550a845b37dd86bfdee42e567c02f2b9e16d2d140720ac6cb9c9dd1f026e0bf7cf9c932a47b37be8efb7" +
+				"1c5f70f2ba422072ec6a32840e0dde13b5c13454c99d13c6bf4639c3f04af889b1a3dd9bfa729d3d0faa5402119361755acefd5a4d54208f686c41abede809d8" +
+				"af7051675d3a0aaccf740ad7956de482894d484e3d3907149b14c45af10ef33b0171e2adb3751e5738c90cf7f4a34c967a4e91173687e3ca504836577753eff2" +
+				"d011b270df0581dbe2c518a8cef577a2f0674e4472683d0c772119fa3662af806674c1ece4b0ff5656cd80ff2f615c19509939cabb041ac47d4a98c2b9b19f06" +
+				"4cfce539d0e64f371adee9c8a499c66cc4843fddcc95d6d079d82ca30006c57e183f8ae0264e679e606d353d90b4a4e0c42c93a0ccb76456ca48225f5013e97f" +
+				"01243229f9f11231eb8b300d3ef90b47db6d5245ffa8426f319fc7e92d787c28413d055ff6ecc8ecd6e2aeb7623ff5721ed0c3100903203381e98700f67191ac" +
+				"53933ee44e310666c155dbcdb89f227c3137edfe9fb8f5f184aa8a6d439354e3200813fdb1a77ef1773a1e0258229c6f6cf3e3a485ba5528e7b1f3669906ed01" +
+				"afa36e8ca08946277d5ae03a91d7a246ee8f746c688094d11b885c8540b24975874d33d8f6d7cfb2817f722f0cdb61a5e27c447f1e7ef47f4e5af09c04ce951f" +
+				"d860968ca9d0c263ab47147c686d179eb853a7b3238e59a7258b655b708fd1817e342c507b1122df3847cd3002936345e6d927d48408030536da855d9648ddca" +
+				"9328b3c2288f678ea04127b9c3e64c5858c8d78d1293451e13df66b0f9e80eab3e9e080d50d8690af01b3086bcd95daade3cc1de7e5107b09ac5fb56f96762bf" +
+				"1803654e42ccdbcb2ad679345d1d34c488844ed4de2a071ce53ba48b822404c8e558711f9fb45685e377b25c8edf993c64ecd40b39f999bd11f1f062d83f3e75" +
+				"7c0ed7cc5ba44620652abca5b79ddfb525e23ecec608650abfe8dc76715e46a7cdc6cec80eb10145641b2816aafd4489febabaa6a4468bd11a03b1207597f34a" +
+				"13594b99b620bf85c21b637e2d4d05d9ed8febb1c6f85f409cacb8e469f4fcafe6b968c29610e2712eb7326611688fcde0e0a7f87307a1adbff634f6fba653ab" +
+				"ca555a8316cd0472eca6e1df430602619adbd337620365ad67a966d782698f439e6d894dede0dbfed4e6a46137728771ef974f575258096444ff98f3ae069704" +
+				"04a7752a3cb9fd17ef851f0402227baa3dac11f61ad5f5460ee8c224ee6f8a715bb20c292c8a08803465f83e459c737b24c5476bf6da19b46c7863b3126ae71e" +
+				"def53eb13074d8a452a4bb7f06738619f6929e02c51d12a3207e133a42387bd0b7452b1d9dff63ef3f96ecd9db5e41ea2468cb252c4e0074ee90ae748eb5b7b9" +
+				"2d081cdbdc3978cd471eec3a5533011b4473b94722883904d7c8d71987cdbe30e4a8d8cd5ee6cac309c1a706dba41bf566d5958f0992590655be5b607b4ae906" +
+				"2d81af0da335b08865c8aac89efe5d48b2abd0bc3e5e75dbbbabffd05f3e93d4fbfb997631b3091ae9ad876d2410c0d99cd4eb004aff88a6e2e102ce0b44aea8" +
+				"ca6f6e62e3b82752513895248eaea65519dcf50bc82cb08cabec070b8018254f076eab013bf207093ab68edf9c99fa57cca6c96de49a4367cb642e9406557ad7" +
+				"e233dd5413d6fea79e1cef265aeb8572c44ae10ab1cce52d07ed456553a56507518e42efcd588c60ef5a6ed596eb4ccdb1dd90c02fa970780d8c5099191b3393" +
+				"bd5db9aede857c58852322caad00afbc78a3b9ad69893b0257fd7845c8cb352d0e5271e62675a712121a290f69272239f0916b66f2eb60b0a6cb993cf84cf45c" +
+				"1d6fd6fbc5ff75ac1c6aea0833be2cae246963e6a7fa57d53d45ea1f53c8b9d8459f4a7293d2859d7d6a7bd8cc9adac7b60c6cd7087d192116ba3ba1ec8db3dd" +
+				"9b4e8a03df93373436a78d3234077d52403b648702f64ea49a69492c1f5f143ce8dc6a7122abebf41a1feb4248e5e8d2572f297dc8ddbf9806d570c60d6772a9" +
+				"936bacba727be5aea9625c0580bdcb903e516926cf9a1835f1a9b41bf84b2e0b9a4749e83c79a7696c69d3940163f40740827e6c833c2abd6fc1852b8f6eb630" +
+				"fae02fb3d72833b632f985dda74df714037eca9db195f337601d1b7aee663364f5384c45f8f68a9cb53d47d2ebfacebd20e322188257bfa269c30f2d2b034c33" +
+				"c5a660ff5c8c8f5ccdfd6a0fe78804361bd15c55486c7a079be4eab9d77ae2afae6c24d6d07deb13c2e9739a71267744b91321cb3bea7ac15d1af2161f86bffd" +
+				"e10d1a14eaf6831385db097b866e8d1c6fdafd631d43a614f5b8e054057ed0b5ff311c62073298ba0595efffcb42bff2094247cc9098056f2740131439e991c2" +
+				"131eb03a47a7ef43a489792bbfb83f255331bfd074a7fc159cb1f353a640ac13019627af6e15848095af9e1dbb7893bc893e739567e665451032029424cca1d5" +
+				"9b002c33ca4af9ed8ea73f967659ba51ecd48bc3911371cfdd1219c5b288edadb02d9fd01de9bbedb3b5a2b9307eb68a672a7101b0dd6e30be193edd08fa66e3" +
+				"66af009d8b8a0885fadeba87f2fedf4c77b4f58d2ab6993cd2b6c3f195d5fa43974bc9130ce18bdebeb6687edceb8fdc1419d24a2f822a367e0074b1fa445882" +
+				"24e7b15cd8085dc9809d5f7b53c980ada5b57458154e2e483987b6857bbdf477785ff1399a6e9aba741b314e509f7e325e83b21815a4055876e05c40654e95b7" +
+				"f72b4786ba5c91d1205b334ad3fd1266c9aee70c05399670c6c06b2b69093d76b58bd306eb179d1deb24c30d018a459f3eff51babe1f9f5a7c3ff9b1f9a8ddff" +
+				"58c2f36ca0bfbd60aa09f9965128029b3583f980b8cb6dc878f4b8c8dd4398b8d7832bbc5bf31f24cc2c83f99c6977108fd50672f4f742af42c0bf58d31e998e" +
+				"70e04bf6e8f89fd574e2f2ea95333da2c2cff4a2db20239f2f6881ceea0a23a86c6e7fdd6a4163327d082ad464c5a59c13daa4c6c3cf1f2dd046f7b8f8bf64e1" +
+				"11dac6766f4e78c4d988e2dfce8a7239c45e5bf4603b523140a6ace36969fb7d6527ae5f34a02ff756918bf812c705bac37f7cc4df6bdb3c119422ce5e09f638" +
+				"703f9c40888c833fa1bb1429927eb10db5850aa9c8c818f72c838f9d75f95bf65fc09949ce34c1bb07b4174649877f0bef2f13f85809b0bf24f662c16fb791d5" +
+				"f23fd7569de3d768e2b3dbd2e57c2f9691579b08b0c72c0a8eac05d68075ff537d5e6424c7b70f755960fd980bf3bce993d441957e85f5646baae79a76203482" +
+				"0fd9c07bc2d553f2083b381343095cd21b03a2eb9684cac9a99ce724f8d2c25c10132e68635800e2ba31964dba59e32ebe98b1d8a5a08ff3d0f9fc13780e048f" +
+				"e2f1e980d9b4862b52cb6f6bd0d9e1ca993578530a6db8fa5f0004c62c695c26f57707bf40212f8f1c88178bf597c073f0cc64aba24d422191308fe21205e572" +
+				"ddb743d11f2ad376bbb0bbb93d9c96a3c41decdd1e0cff1782285a8fe49547dbb4e36a1aa18c0ef881dbb222f41a6fea4d0ad43caa4bea001af1e66fe88b618a" +
+				"083905450e85550bcd8a22820694fbc31c1b548f31f18f12868dcb549b5054b20c557af86002055723c4c5ca8b5801a653ed9f1e3a35462f5e401a26ad35bf33" +
+				"15b5788d918d2999fd286fec178aaef52b4639119f7396205d810a9f0811ec6c66c2655ad791352ef9d4a666dbe2c6ce16fde8bd719fc2627673ca7f17e89374" +
+				"52acfee79cdd7db312d68605f31e2f293e8aa1a2b7046fea84c2fdf7539a71afd0245baaba2c9eb2f4f2518f8d607ec7668a545468d3743b331f457d5dbe4c77" +
+				"960ebe7481fc66164b3aba0e194f6fb05206993d5f6418709235b32d39f65e9ff333d92b4219f3e7970ebb43d2efe7c82c37822bb9afd5978322252471993880" +
+				"105110c7f5592e1406150a125b707e3c26be64a32f628e2433c60ec744c708698ba2a4fd97b3d36ac683a7afdda79bbf63b3717054344acfdc42563adac38c3a" +
+				"0f98f746515ac4ffb06d851b940c1c0143cc4e209a30c0e4f98474bf0550fa4f5f6acb0466f327446250f20817623a9dd9c128499872ebd95239769d13eecc62" +
+				"c34471b5d6d26854fbebfb0fb6f1e0b6f0d79cab60fcd6d82beb13c9ea82f623c3be1f69ae5bcd026db12c88583ccb6a89bea6697377643c87f7bc8b884aa9f3" +
+				"f434df4a57ae40414dadfcec00d5220f463af987fc4bf103cf33aa8210f7560da5aa8431cd763a5a43815ed5243a76389cdbe13aa0b566a8d2e589cf20f47a40" +
+				"8ae723ba9627ad42ae3f41c5be63ec1e2c2cbd8316ba98686f010a87b30e5ec152619bf8908466982c0edc06b70ed64c77bc7019a72cb1e2669d07a864e691e7" +
+				"389e2dc5c67daca1eb6b7c9a678d590af412b1a0165bd101becdc79ef4eea2f1a4885fa80dbe2d163cbf47febcb3fe901def6ed43ef8c47601d7ffc86fad9a60" +
+				"fb7f92bb5b563d95fddf13d0ab9cd6e1ab0fe9d9633ddf5f72065c382c71388762ee9b9dd82457203f9eb8aa8cb425d1a2afdda1260776a74342e8714a579510" +
+				"6e4699e2ad48803c83115c77a8dbf82af9372da3e0f5fa8c2aa421beddca2ad05889ba3c4fdb94818746827edc3e9a5063e30f843c17a8fe3d3633d4fc7a6b03" +
+				"ca7fbb0e9a3a14e353ab6bbc1e45d3c5ee7631789dbcb70063abefc72639452aa7c4e4df68438c8bc9755edb661feca34d33bb2ad3ff50c1b232b7b828a3a908" +
+				"c7e60bed09cd2ec32c0a679cd87c49314c033886f02fc51d5200814613a4775f655c71e9be8c7bf99e7d692dc58ecfaeebcfdc52952a2e3f2f35bf5cfccae76e" +
+				"79aa32b61a95140c130c3344d86a16ed15d008692b5bcf996a2fdafee597a4c94a1d2d88fb5ea5d41edd26f637ae30ed3edb5aebdd1fa4cb3735637c10b9847b" +
+				"20442c95619993eb3aae124d824bcedaefeb076e120bc41a8c76dece09f60d5a52adac49a7ccea96080989812aadb0fe27b0c610c99ac1ca733efb2c8da42fb9" +
+				"1bd352a71f09f84054e760735c0f0a1546b8584ca570adfc21dfacf932c158027ac5c999eb1bdb9e89aa5e74276b18b5c62d0b0bd97dd5f158a0a4e1863ccc65" +
+				"766ae97204794782113b0cb8a1d7805381eecc780ba3ed0dd4ee4561f5e90bcecddfad31be019fe328e0cd0b8fb1cf420669f7257a76f53e26a37fa2f0e1bbc4" +
+				"23aba17d95085281207a860bc508fabe973656126d10c425e98a90687f57c56c860cbf20d9e09333bafb853de882d42696bd3bb8fabafeaf13e2985781adc412" +
+				"46463f4b737c50be9811576f679ce53da66cf5658e834ad935e1dfd637d718bd9b212ef07dbd70fce40e84be85ea2c887304ed5dfbff759e05df974176748a17" +
+				"a5ccc8ea59d1fab93d92935724bdb89864147f2c46116c359c022120025e42d5e152c8d73bad97ba1dbd72ded901f25a1d81976422029b42f1d1a29cad5411c3" +
+				"dec60b2d936f732b7181fed1eb7697a098461e195bf0704d4b331d24426d4cc73c9da02764fd8643977e0fc10e7f2c0094081f32b180d2b5f8040820cbf3d160" +
+				"bacb7aef21067cd3fadd257b09bdfbea77eca6dbe4ca455f9f08daeda1851037a14a49abbc18d203eb3ef65efff4844b247a3cd6acc3fb5177b88232c8b511ca" +
+				"9f244b6adb4007dea319c2a5cbed46c2f77f9cb7296b668dea81c22f49807ef76b69ca4a2c8accc03ab2b20b649e2f69859aca407a0ab9d7d7b5746351b6cbfb" +
+				"0b5748ea7b952b65a404ec5cec5b1fafac12f1cfcf5fc593aff99902c4b24f71c8288815dda8d6364357bc9f49c0df5cb434c3d150cbb91f58ba59390e6d9509" +
+				"566f3b6522b9586a5c6d1c8965e74754cf9b6daec9030ea163d446cc7a6d2e89c4fc64d36df443ba0b616831080c2ecba5f6ff0e40bd77de07396d3b8d78ca9d" +
+				"111ba616f9e7969e3f3339f86e3ff0aaddb19fde985b8870ce3a64d84d88c690a01623f6c205e5047c98999d1ea53e11ef35003618347ca6f637c48a38ecfffc" +
+				"475c0be553386858114c9e6e10c1505f6f92320f565f7c8e4875fc89590257ecbb4575a728fe56164012fb7e55d7e792686cd521766a862aab969c9c1dc6801c" +
+				"cc73b3ae10d294815549dc3cdc3d685581c84662cf03c68415a4a34e8a48f7b42dc6fb18331b24fd87991119b5c8deeb0951f468b5fd8a4ed6567e0cfb6dfbf5" +
+				"dfd8b5040d25f1d1ddaf9412c5aeb11c93b3983bea087e8fdfa3c7aeb4a7b10bf3e32f340c349a37a6cf453029f0560319083375219ebfd9c6f6846886e40e8f" +
+				"0f5fd51ab38f561a651dc9efc92dd13ab6598149fb09f29b41bcac81cc11befb5cec62ce287e02e0cf76e3930018432f82335d00b16417895c7f43cfce80fc6b" +
+				"4fb26ae67e4392aa946d29b53eed2665f04400066a5bdcc4ed198d09deb8b03c8b7fe37d7786e142590e893e01afce4d87e202ccff5dacb67e2750455292dbb8" +
+				"716d710cd1301f7acf54c764dce6558792ec3dfc02f0ba0ea5463e33192ec672fb8ffcab6ff66dcbc47aaebc82ab51ad07ba2955a9b113110ee529949fba156d" +
+				"2c9a765ecd9fd78bb017a121b51cdf6464146245c57ff0da451447d508a0e16c8810e92eecbacbde3a09c4da1d0b999adc79946ae7b7ae6eaf1b08ee541db453" +
+				"788917b91d955b1283ca4c954fccb9d8127c9bd58aafdcd2544ed7c8251de77302f5b139fde21c81a40b9fc572df64001fe3b773efef2cc73da8f2e5a453df59" +
+				"0422471314eede15c92a84caeea90350591626258768169621e90775eb86d506fb691f58d08f695e7c68075ff40ec5f7fda8ea2384c7db8ff2aab7cec0d83a85" +
+				"d3768f0677b1f098f0d885114067139eeec4a659b10f79401d95cea78c921e7d6bc1b13fb39f5ac2422027b3cd3eb0d298d3f5510b3e3f30076b4802000caa3d" +
+				"b68779bee03a6bac1b6f274d288aa77099ef1345ad0e2d6310b16df7a5d57bc1488c86deed2ca97833cccc3144ad12b32ae58c1739d277525b77f6c07768625c" +
+				"fbec3f463f72cfef6e290c277543e9ce560f166756a83da0a7d885eab8d16edfa20f5b032b377a305634711616e65807fb38cababa5b08090be6948aeca5f7bf" +
+				"086d70d2e7647ddb60b97ae30061f4d950b228131890558c19212abc4ab0c2e812639e977bd1dc9113e4942be6d9b54bb71927ed3117b2859809a2b281a1db9e" +
+				"4555c7bdb385572c13e8d88f4430ec50d9a032dafef2c8c0fab44d167655f0a4a3c96117245c0cf5f6a5e837d5b9ffd385ec0d606e13fb2d4778eb081f0beef1" +
+				"a8c87b48d0f98078cdef6e633041d5fc8bb787c81762db4e45579770587faa131c491519717c302ec32956b277707935610c056e8e9864c87d718fa93d88c003" +
+				"cc339874965f2a2d81a6257b61768ca992fca1dc12e934787fd45d2c7c12e25a35f8dcafed1e95a0f4ffff8f1231f258d8d4ad79548bd74892f3f407631883fe" +
+				"0ddbb234d5d7df5d04eaf32f5500792c3d0a9f21556fe4407ff19ea5e86243c2d1e7fc2e221461eadeeebcac086405fa6241474e992cab42856f9a4dec2e94d8" +
+				"a84ab72a672c855eb12c654fcf3a60a55bdc7f5bd955fda70275dac9b82cd7a75b7cade61d94aca3972192b07f3d4e7e4e4036c0b1db7c9be45eeb17d3f0198e" +
+				"05fb11ec5a027aac3a0cb1ef9de391ea621fe2b4633d34449fa7aa7af81b76e74b942748e30fb785586dbe6f6436148314605a4b2602575543a673db9788c57a" +
+				"9df726209835acd244575213ba3543cbb647fece091b7c71e6269f58bcbdeac6497e8bfcbef7b842849f012dd35abafa2205cb5a65aee580ee537ca0ae4bfc06" +
+				"d5074f69349ed6b339374e3695b4ab371ecd5a4a9af50e0d840d94e2500f9c6c991c0970cd4638a037e5997e1062789feeb41c578097fba4e0118800232a760c" +
+				"486243c25e8c45455580506e415fbe18a366d8ee2e14f6a44aaa0ed84ab6aa35aedf6ee54c5c8b5195da9798390229ea8a08096f14265fc11da3847a069f9cf0" +
+				"6727a2c10d41cfd2f5f6380a36728fee0a874aff2c29c3d05f136528eef4f214cbb3c755f63661703db55e9cd75d54c99076cb2aa44cc6eb2783fd003de09291" +
+				"6ab148f4314dc55ba280cf3a9e193fefe52f9e2a1d9834d485dbde5d762fd640a2448a928dc769284a36e06d5d2beeb11296c3c3ab66236e052df161083dc568" +
+				"6ed67a29132ea3fdb645f11e8d2933caf4c2551f0ea1a63af4999c2a1f745023200798562f013132177ae0052ce4b4baf0f1c5c85369edca0144b53255ece0d7" +
+				"6ba0de4f93a72c7b3d1cb6d5603781714ada86f20237ac4dc6f922a2250855bc788d414582c6095fb4bfc626981dd23d87473b433c1deb5c4bf7e801b0960af4" +
+				"6215338f4dc4b9101cfe0fcc59bc1efb1fc7dfa88f5ffefd756d704917f07df996917036264a00d2b4f97e58db6f712f2e5f50d4bd011f04804373289eca6e29" +
+				"1a46eed0517137b7a5adcf78aec557d1390da8b7a3f73cc04279209fa7d7a9ab991e9a8dad8cf526d637951cbd07188cbe08b52ce97b1a40aba0e78d11b909e3" +
+				"963877e7077af25bcb41aae0f4a6602ee7ad70ed61ba874dffb43555cb48eba403afa812f3b76a8f26b01dbc4abf06abbd3ad3005eb0a46885e8611cefd82f82" +
+				"9828905e75cc71dfbc72c2228df2717c2c1c8368c459d72b4931bdcbda902da3d07ed1ab6eab0aa31bdd9e6d8d427ec4df4320fdfbd20f98f8efa41530cc1751" +
+				"2ff8291808cb4e7cf8744a52ed23b413ee2d7b69a6fa8292afaa2530695d501e8fadcca5164d57c90089d28162f179c393f5eb2bd4a6294b884d7f1f2256f3d0" +
+				"301089baad369e2563c8a53ea9e4851d85792fe594c26ef6efe4e061631106315c3156fec321179456c63c28689f6ac761c71f82bb3f8730583e0842337f2d73" +
+				"241183a0a017ee57ca01647a0052dd07a4b5329f4837afb215a38d3a40ed2b1b2186385fe066184e2ea349b8d742f188c326d64ab498566e3c6c619f44aa2971" +
+				"e9ede47d6e40d1fc5a9cf5a02071d633888d14e710e012cd13e6db14f09e91a37fc7efa55d23f2514e865cfae86f27c0a424e7401826130ed1d274b5948a6ea2" +
+				"bf17c3fdcb72f6280d24002b9af901c738b6be8e1e5bc660106863b418c52ec56b598df060772073c6150025695ad5d81dc79b7b8c232131f0b648e0f8f372c0" +
+				"9c6d917e597b3014420b2750e01ebebafae1c6547e0ceca8e3b2e111bf2e63d534d5ea4e8955261c0b56a505ad0e0ed05d8e92b24b5ea5c76865c20dd93aa764" +
+				"50ad035cdaa505231347951e88b51262bdde7eb05b7eef5d5993baf39ce4e4f42a28ba060844bf3be2d2f9108e2f6d048472e8d86f1c17d2ddf1e6e8c75ee6ad" +
+				"94e08b339a19bc19ed942807cc0bfd607c6a23c6cc9a9fd3d4fbae4c57e6a3090764d16a57402af293874013149a1997eb1daa11c4d10cef14d8da3801df0921" +
+				"34f5c3f4d9ed300388fc13ea45f663c5b03e9edc25603ed9ff3db6fe7db8e18d918b057220eadc70e338535ea9da96baebdb2e7b0b53896e733ba6710fcc3543" +
+				"e6454a7bda6e1018e5b128e45abe15e80b498a095951ac91e700955bc9e047e590da0b15ba28db464f5d3836d25ebac445f54b53e99d064ee5ffb3540ff0b5dd" +
+				"b8a5aea6759d990f91e903609035eb9afcdfe8537d00d8174463f22ecdf23e8ee79c0519f6d1dc9d7c1bccb93cf790f0bd6de2f2a093322e5cb680638d069d1f" +
+				"e2a958b2a07f8d8210bc6c182b4655b3dcf3fda5594be8cb8bcdeaf9429c7544423bbf82ffebb32cd9c96fa821651f8afe100cb12d01768b045fc4403a0ed676" +
+				"8fa6a0fe5334d0241692d99dafc4a83d38eb53ca2fce1dcf0f82e563d396e6cf8efa5da4d8b3ab705acb1bad51f1b140672cfb2d2fdf2b747ae9c83d7a9f9d37" +
+				"fdbe860c03b7b70f70cb7c5ec7b3e93bc0354ad176dc895a2ddf00220bd72bc79b1b7fdc82c3f8366e2064487447a717ecd9703c27583561b6e90d286248cb4a" +
+				"3570751e92a0bd14068773551fab672cddab3fcd7c15bb11ffcc06a1b84dfb04f6f4e8cdcf6316dde127638693c7bf3edcdf46120b848ec6236b383089d15a2c" +
+				"4b1ea22f551cfcc1afd2d48efc417aad773a391fc5131578cd37941b95282e184f4927ba4c48de0ab7b2527a9ce8e904119d748f323e50d59223b5cf2a5a109b" +
+				"cb62cce77c6ec99cb34591a8f05d0ca5b725f9de7cabad6d43d698f53869ab0da6f0490f442380283da8c952f7c86f1dad7ff6e3ae7d7a3473166072dc237fbe" +
+				"16f2262d",
+		},
+	}
+	msg := []byte("This is a test for XMSS.")
+	for _, v := range vectors {
+		skseed, err := hex.DecodeString(v.skseed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		skprf, err := hex.DecodeString(v.skprf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubseed, err := hex.DecodeString(v.pubseed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		params, err := XMSSParametersByName(v.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sk, pk := newXMSSKeyPair(*params, skseed, skprf, pubseed, 0, 0)
+		if hex.EncodeToString(pk.root) != v.root {
+			t.Errorf("root of %s is incorrect", v.name)
+			t.Log(hex.EncodeToString(pk.root))
+		}
+		sig := mustSign(t, sk, msg)
+		if hex.EncodeToString(sig) != v.sig {
+			t.Errorf("%s sig is incorrect", v.name)
+			t.Log(hex.EncodeToString(sig))
+		}
+		if !pk.Verify(sig, msg) {
+			t.Errorf("%s verification is incorrect", v.name)
+		}
+		if pk.Verify(sig, []byte("This is another message.")) {
+			t.Errorf("%s verification must fail for another message", v.name)
+		}
+	}
+}
+
+func TestPublicKeyMarshalBinary(t *testing.T) {
+	skseed, err := hex.DecodeString("b041bf7ca73cc7905aadc1b6460da2e50206652e3d57a61487beb09664da308d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	skprf, err := hex.DecodeString("303f46b2e4248b040a74e7f1eca3ff288b452ef4910739b466225ccadcdb3aae")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubseed, err := hex.DecodeString("115e28fb34d6414cd7cce714895df8c4a674ee1e557f1f3df99370aa8ab7c404")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sk, pk, err := NewXMSSKeyPairWithParams(10, skseed, skprf, pubseed, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := pk.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(b) != "00000001"+
+		"a959a891573da8633b89e8f21e43eef9fca43a14bd2d71b1cf9ad5706945e752"+
+		"115e28fb34d6414cd7cce714895df8c4a674ee1e557f1f3df99370aa8ab7c404" {
+		t.Error("encoding of public key is incorrect")
+		t.Log(hex.EncodeToString(b))
+	}
+
+	pk2 := new(PublicKey)
+	if err := pk2.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+	if pk2.XMSSParameters != pk.XMSSParameters {
+		t.Errorf("parameters are incorrect: %s", pk2.XMSSParameters)
+	}
+	msg := []byte("This is a test for XMSS.")
+	sig := mustSign(t, sk, msg)
+	if !pk2.Verify(sig, msg) {
+		t.Error("XMSS verification after unmarshal is incorrect")
+	}
+	if pk2.Verify(sig[:len(sig)-1], msg) {
+		t.Error("XMSS verification must fail for a truncated signature")
+	}
+
+	if err := pk2.UnmarshalBinary(b[:len(b)-1]); err == nil {
+		t.Error("public key with invalid length must not be decoded")
+	}
+	b[3] = 0x0d
+	if err := pk2.UnmarshalBinary(b); err == nil {
+		t.Error("public key with unknown OID must not be decoded")
+	}
+	_, pk3 := NewXMSSKeyPair(3, skseed)
+	if _, err := pk3.MarshalBinary(); err == nil {
+		t.Error("public key without OID must not be encoded")
+	}
 }
 
 func TestXMSSExportImport(t *testing.T) {
@@ -137,7 +611,10 @@ func TestXMSSExportImport(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	privKey, pubKey := NewXMSSKeyPairWithParams(10, skseed, msgSeed, pubSeed, 0, 0)
+	privKey, pubKey, err := NewXMSSKeyPairWithParams(10, skseed, msgSeed, pubSeed, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	if pubKey.Height != 10 {
 		t.Errorf("Height is not 10: %d", pubKey.Height)
@@ -167,12 +644,12 @@ func TestXMSSExportImport(t *testing.T) {
 
 	msgDigest := []byte("test message")
 
-	sig := privKey.Sign(msgDigest)
+	sig := mustSign(t, privKey, msgDigest)
 	if !pubKey.Verify(sig, msgDigest) {
 		t.Error("XMSS verification before export is incorrect")
 	}
 
-	tmpSig := privKey.Sign(msgDigest)
+	tmpSig := mustSign(t, privKey, msgDigest)
 	if !pubKey.Verify(tmpSig, msgDigest) {
 		t.Errorf("Second XMSS verification before export is incorrect")
 	}
@@ -185,7 +662,9 @@ func TestXMSSExportImport(t *testing.T) {
 	}
 
 	priv2 := new(PrivateKey)
-	priv2.Import(skExport)
+	if err := priv2.Import(skExport); err != nil {
+		t.Fatal(err)
+	}
 	pub2 := new(PublicKey)
 	pub2.Import(pkExport)
 
@@ -193,7 +672,7 @@ func TestXMSSExportImport(t *testing.T) {
 		t.Error("XMSS import is incorrect")
 	}
 
-	sig2 := priv2.Sign(msgDigest)
+	sig2 := mustSign(t, priv2, msgDigest)
 	if !pub2.Verify(sig2, msgDigest) {
 		t.Error("XMSS verification after import is incorrect")
 	}
@@ -236,7 +715,7 @@ func TestXMSSMultiSigning(t *testing.T) {
 
 	i := 0
 	for float64(i) < math.Pow(2, 3) {
-		sig := priv.Sign(msgDigest)
+		sig := mustSign(t, priv, msgDigest)
 		if !pub.Verify(sig, msgDigest) {
 			t.Errorf("XMSS verification is incorrect (%d)", i)
 		}
@@ -263,7 +742,10 @@ func TestXMSSSingleSteps(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	privKey, pubKey := NewXMSSKeyPairWithParams(10, skseed, msgSeed, pubSeed, 0, 0)
+	privKey, pubKey, err := NewXMSSKeyPairWithParams(10, skseed, msgSeed, pubSeed, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	if pubKey.Height != 10 {
 		t.Errorf("Height is not 10: %d", pubKey.Height)
@@ -291,7 +773,7 @@ func TestXMSSSingleSteps(t *testing.T) {
 		t.Errorf("PrivateKey is not correct -> Index: %d", privKey.m.leaf)
 	}
 
-	sig := privKey.Sign([]byte("Test Nachricht"))
+	sig := mustSign(t, privKey, []byte("Test Nachricht"))
 	if strings.ToUpper(hex.EncodeToString(sig)) != exSig {
 		t.Errorf("Signature is not correct: %X", sig)
 	}
@@ -326,4 +808,414 @@ func TestXMSSSingleSteps(t *testing.T) {
 	if !verified {
 		t.Error("Verification failed")
 	}
-}
\ No newline at end of file
+}
+
+func TestXMSSSigner(t *testing.T) {
+	skseed, err := hex.DecodeString("b041bf7ca73cc7905aadc1b6460da2e50206652e3d57a61487beb09664da308d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, pub := NewXMSSKeyPair(2, skseed)
+	var signer crypto.Signer = priv
+	if signer.Public().(*PublicKey) != &priv.PublicKey {
+		t.Error("public key of signer is incorrect")
+	}
+
+	msg := []byte("test message")
+	sig, err := signer.Sign(nil, msg, crypto.Hash(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pub.Verify(sig, msg) {
+		t.Error("XMSS verification of message is incorrect")
+	}
+	digest := sha256.Sum256(msg)
+	sig, err = signer.Sign(nil, digest[:], crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pub.Verify(sig, digest[:]) {
+		t.Error("XMSS verification of digest is incorrect")
+	}
+	if _, err := signer.Sign(nil, msg, crypto.SHA256); err == nil {
+		t.Error("digest with invalid length must not be signed")
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := signer.Sign(nil, msg, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := signer.Sign(nil, msg, nil); err != ErrKeyExhausted {
+		t.Errorf("exhausted key must not sign: %v", err)
+	}
+}
+
+func TestXMSSKeyExhaustion(t *testing.T) {
+	skseed, err := hex.DecodeString("b041bf7ca73cc7905aadc1b6460da2e50206652e3d57a61487beb09664da308d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, pub := NewXMSSKeyPair(3, skseed)
+	var calls []uint64
+	priv.SetLowWatermark(2, func(remaining uint64) {
+		calls = append(calls, remaining)
+	})
+	msg := []byte("test message")
+	for i := uint32(0); i < 8; i++ {
+		if priv.Index() != i || priv.Remaining() != uint64(8-i) {
+			t.Errorf("index %d and remaining %d are incorrect", priv.Index(), priv.Remaining())
+		}
+		if !pub.Verify(mustSign(t, priv, msg), msg) {
+			t.Errorf("XMSS verification is incorrect (%d)", i)
+		}
+	}
+	if priv.Remaining() != 0 {
+		t.Errorf("remaining %d is incorrect", priv.Remaining())
+	}
+	if _, err := priv.Sign(nil, msg, nil); err != ErrKeyExhausted {
+		t.Errorf("exhausted key must not sign: %v", err)
+	}
+	if len(calls) != 3 || calls[0] != 2 || calls[1] != 1 || calls[2] != 0 {
+		t.Errorf("low watermark calls are incorrect: %v", calls)
+	}
+}
+
+func TestXMSSDelegate(t *testing.T) {
+	skseed, err := hex.DecodeString("b041bf7ca73cc7905aadc1b6460da2e50206652e3d57a61487beb09664da308d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, pub := NewXMSSKeyPair(5, skseed)
+	msg := []byte("test message")
+	sigs := make([][]byte, 32)
+	for i := range sigs {
+		sigs[i] = mustSign(t, ref, msg)
+	}
+
+	priv, _ := NewXMSSKeyPair(5, skseed)
+	for i := 0; i < 3; i++ {
+		mustSign(t, priv, msg)
+	}
+	if _, err := priv.Delegate(30); err == nil {
+		t.Error("more keys than remaining must not be delegated")
+	}
+	subA, err := priv.Delegate(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	subB, err := priv.Delegate(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if priv.Index() != 20 || subA.Index() != 3 || subB.Index() != 13 {
+		t.Errorf("indices %d, %d and %d are incorrect", priv.Index(), subA.Index(), subB.Index())
+	}
+	if subA.Remaining() != 10 || subB.Remaining() != 7 {
+		t.Errorf("remaining %d and %d are incorrect", subA.Remaining(), subB.Remaining())
+	}
+
+	der, err := MarshalPKCS8PrivateKey(subB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ParsePKCS8PrivateKey(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	subB = key.(*PrivateKey)
+	if subB.Index() != 13 || subB.Remaining() != 7 {
+		t.Errorf("index %d and remaining %d after parsing are incorrect", subB.Index(), subB.Remaining())
+	}
+
+	for _, k := range []struct {
+		priv       *PrivateKey
+		start, end int
+	}{
+		{subA, 3, 13},
+		{subB, 13, 20},
+		{priv, 20, 32},
+	} {
+		for i := k.start; i < k.end; i++ {
+			sig := mustSign(t, k.priv, msg)
+			if !bytes.Equal(sig, sigs[i]) {
+				t.Errorf("signature %d of the key [%d, %d) is incorrect", i, k.start, k.end)
+			}
+			if !pub.Verify(sig, msg) {
+				t.Errorf("XMSS verification is incorrect (%d)", i)
+			}
+		}
+		if _, err := k.priv.Sign(nil, msg, nil); err != ErrKeyExhausted {
+			t.Errorf("key [%d, %d) must not sign outside of its range: %v", k.start, k.end, err)
+		}
+	}
+}
+
+func TestXMSSInitMerkleAt(t *testing.T) {
+	skseed, err := hex.DecodeString("b041bf7ca73cc7905aadc1b6460da2e50206652e3d57a61487beb09664da308d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, _ := NewXMSSKeyPair(5, skseed)
+	msg := []byte("test message")
+	sigs := make([][]byte, 32)
+	for i := range sigs {
+		sigs[i] = mustSign(t, ref, msg)
+	}
+
+	for leaf := uint32(0); leaf < 32; leaf++ {
+		priv, _ := NewXMSSKeyPair(5, skseed)
+		if root := priv.initMerkleAt(leaf, 0, 0); !bytes.Equal(root, ref.root) {
+			t.Errorf("root at leaf %d is incorrect", leaf)
+		}
+		for i := leaf; i < 32; i++ {
+			if sig := mustSign(t, priv, msg); !bytes.Equal(sig, sigs[i]) {
+				t.Errorf("signature %d after initializing at leaf %d is incorrect", i, leaf)
+			}
+		}
+	}
+
+	priv, _ := NewXMSSKeyPair(5, skseed)
+	priv.skipTo(4)
+	priv.skipTo(29)
+	if sig := mustSign(t, priv, msg); !bytes.Equal(sig, sigs[29]) {
+		t.Error("signature after skipping is incorrect")
+	}
+}
+
+func TestXMSSBDS(t *testing.T) {
+	skseed, err := hex.DecodeString("b041bf7ca73cc7905aadc1b6460da2e50206652e3d57a61487beb09664da308d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("test message")
+	for _, height := range []uint32{5, 6} {
+		params := defaultXMSSParameters(height)
+		if _, _, err := NewXMSSKeyPairWithBDS(&params, skseed, height+1); err == nil {
+			t.Errorf("k = %d must be rejected for height %d", height+1, height)
+		}
+		if _, _, err := NewXMSSKeyPairWithBDS(&params, skseed, height-1); err == nil {
+			t.Errorf("k = %d must be rejected for height %d", height-1, height)
+		}
+		ref, pub := NewXMSSKeyPair(height, skseed)
+		sigs := make([][]byte, 1<<height)
+		for i := range sigs {
+			sigs[i] = mustSign(t, ref, msg)
+			if !pub.Verify(sigs[i], msg) {
+				t.Errorf("signature %d of height %d is incorrect", i, height)
+			}
+		}
+		for k := height & 1; k <= height; k += 2 {
+			priv, _, err := NewXMSSKeyPairWithBDS(&params, skseed, k)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range sigs {
+				if i == len(sigs)/3 {
+					priv2 := new(PrivateKey)
+					if err := priv2.Import(priv.Export()); err != nil {
+						t.Fatal(err)
+					}
+					if priv2.m.k != k {
+						t.Errorf("k = %d is not restored: %d", k, priv2.m.k)
+					}
+					priv = priv2
+				}
+				if sig := mustSign(t, priv, msg); !bytes.Equal(sig, sigs[i]) {
+					t.Errorf("signature %d of height %d with k = %d is incorrect", i, height, k)
+				}
+			}
+		}
+	}
+}
+
+func TestXMSSBatchVerify(t *testing.T) {
+	skseed, err := hex.DecodeString("b041bf7ca73cc7905aadc1b6460da2e50206652e3d57a61487beb09664da308d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, pub := NewXMSSKeyPair(3, skseed)
+	sigs := make([][]byte, 8)
+	msgs := make([][]byte, 8)
+	for i := range sigs {
+		msgs[i] = []byte{byte(i)}
+		sigs[i] = mustSign(t, priv, msgs[i])
+		if !pub.VerifySequential(sigs[i], msgs[i]) {
+			t.Errorf("sequential verification of signature %d is incorrect", i)
+		}
+	}
+	sigs[2] = append([]byte(nil), sigs[2]...)
+	sigs[2][100] ^= 1
+	msgs[5] = []byte("tampered")
+	sigs[6] = sigs[6][:10]
+	if pub.VerifySequential(sigs[2], msgs[2]) {
+		t.Error("sequential verification of a tampered signature must fail")
+	}
+	for _, opts := range []*Options{nil, {Concurrency: 1}, {Concurrency: 4}} {
+		for i, ok := range pub.BatchVerify(sigs, msgs, opts) {
+			if ok != (i != 2 && i != 5 && i != 6) {
+				t.Errorf("result %v of signature %d with %v is incorrect", ok, i, opts)
+			}
+		}
+	}
+	if len(pub.BatchVerify(nil, nil, nil)) != 0 {
+		t.Error("results of an empty batch are incorrect")
+	}
+}
+
+func TestVerifyBatch(t *testing.T) {
+	skseed, err := hex.DecodeString("b041bf7ca73cc7905aadc1b6460da2e50206652e3d57a61487beb09664da308d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	privA, pubA := NewXMSSKeyPair(3, skseed)
+	privB, pubB := NewXMSSKeyPair(2, generateSeed())
+	pubA2 := new(PublicKey)
+	pubA2.Import(pubA.Export())
+	msg := []byte("test message")
+	items := []VerifyItem{
+		{pubA, msg, mustSign(t, privA, msg)},
+		{pubB, msg, mustSign(t, privB, msg)},
+		{pubA2, msg, mustSign(t, privA, msg)},
+		{pubB, []byte("tampered"), mustSign(t, privB, msg)},
+		{pubB, msg, mustSign(t, privA, msg)},
+		{nil, msg, mustSign(t, privA, msg)},
+		{pubA, msg, nil},
+	}
+	want := []bool{true, true, true, false, false, false, false}
+	for _, opts := range []*Options{nil, {Concurrency: 1}, {Concurrency: 4}} {
+		for i, ok := range VerifyBatch(items, opts) {
+			if ok != want[i] {
+				t.Errorf("result %v of item %d with %v is incorrect", ok, i, opts)
+			}
+		}
+	}
+}
+
+//errReader returns err after the first n bytes of a message.
+type errReader struct {
+	n   int
+	err error
+}
+
+func (r *errReader) Read(p []byte) (int, error) {
+	if r.n == 0 {
+		return 0, r.err
+	}
+	if len(p) > r.n {
+		p = p[:r.n]
+	}
+	r.n -= len(p)
+	return len(p), nil
+}
+
+func TestXMSSSignReader(t *testing.T) {
+	skseed, err := hex.DecodeString("b041bf7ca73cc7905aadc1b6460da2e50206652e3d57a61487beb09664da308d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, _ := NewXMSSKeyPair(3, skseed)
+	priv, pub := NewXMSSKeyPair(3, skseed)
+	msg := make([]byte, 100000)
+	if _, err := rand.Read(msg); err != nil {
+		t.Fatal(err)
+	}
+
+	errRead := errors.New("read error")
+	if _, err := priv.SignReader(&errReader{n: 1000, err: errRead}); err != errRead {
+		t.Errorf("error %v of the reader is not returned", err)
+	}
+	if priv.Index() != 0 {
+		t.Errorf("index %d must not be used after a read error", priv.Index())
+	}
+	sig, err := priv.SignReader(bytes.NewReader(msg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sig, mustSign(t, ref, msg)) {
+		t.Error("signature of the reader is incorrect")
+	}
+	if ok, err := pub.VerifyReader(sig, bytes.NewReader(msg)); !ok || err != nil {
+		t.Errorf("verification of the reader is incorrect: %v", err)
+	}
+	if ok, err := pub.VerifyReader(sig, bytes.NewReader(msg[1:])); ok || err != nil {
+		t.Errorf("verification of another message must fail: %v", err)
+	}
+	if _, err := pub.VerifyReader(sig, &errReader{n: 1000, err: errRead}); err != errRead {
+		t.Errorf("error %v of the reader is not returned", err)
+	}
+}
+
+func TestXMSSAllocs(t *testing.T) {
+	priv, pub := NewXMSSKeyPair(10, generateSeed())
+	priv.SetConcurrency(1)
+	pub.SetConcurrency(1)
+	msg := []byte("This is a test for XMSS.")
+	var sig []byte
+	allocs := testing.AllocsPerRun(100, func() {
+		sig = mustSign(t, priv, msg)
+	})
+	if allocs > 1 {
+		t.Errorf("Sign allocates %v times, want only the signature", allocs)
+	}
+	allocs = testing.AllocsPerRun(100, func() {
+		if !pub.Verify(sig, msg) {
+			t.Error("XMSS sig is incorrect")
+		}
+	})
+	if allocs != 0 && !raceEnabled {
+		t.Errorf("Verify allocates %v times", allocs)
+	}
+}
+
+func TestParseSignature(t *testing.T) {
+	skseed, err := hex.DecodeString("b041bf7ca73cc7905aadc1b6460da2e50206652e3d57a61487beb09664da308d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, pub := NewXMSSKeyPair(4, skseed)
+	msg := []byte("test message")
+	for i := uint32(0); i < 3; i++ {
+		auth := make([][]byte, len(priv.m.auth))
+		for j, a := range priv.m.auth {
+			auth[j] = append([]byte(nil), a...)
+		}
+		b := mustSign(t, priv, msg)
+		sig, err := ParseSignature(&pub.XMSSParameters, b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sig.Index() != i {
+			t.Errorf("index %d of signature %d is incorrect", sig.Index(), i)
+		}
+		if !bytes.Equal(sig.R(), b[4:4+pub.N]) {
+			t.Errorf("r of signature %d is incorrect", i)
+		}
+		if len(sig.WOTS()) != wlen(int(pub.N)) || !bytes.Equal(sig.WOTS()[0], b[4+pub.N:4+2*pub.N]) {
+			t.Errorf("WOTS+ signature of signature %d is incorrect", i)
+		}
+		if len(sig.AuthPath()) != int(pub.Height) {
+			t.Errorf("length %d of the auth path of signature %d is incorrect", len(sig.AuthPath()), i)
+		}
+		for j, a := range sig.AuthPath() {
+			if !bytes.Equal(a, auth[j]) {
+				t.Errorf("node %d of the auth path of signature %d is incorrect", j, i)
+			}
+		}
+		if bb, err := sig.MarshalBinary(); err != nil || !bytes.Equal(bb, b) {
+			t.Errorf("marshaled signature %d is incorrect: %v", i, err)
+		}
+		b[0] = 0xff
+		if sig.Index() != i {
+			t.Error("parsed signature must not share memory with its input")
+		}
+	}
+	b := mustSign(t, priv, msg)
+	if _, err := ParseSignature(&pub.XMSSParameters, b[1:]); err == nil {
+		t.Error("signature of invalid length must not be parsed")
+	}
+	b[0] = 0xff
+	if _, err := ParseSignature(&pub.XMSSParameters, b); err == nil {
+		t.Error("signature with an out of range index must not be parsed")
+	}
+}